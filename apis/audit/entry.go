@@ -0,0 +1,4 @@
+package audit
+
+type API struct {
+}
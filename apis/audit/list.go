@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/common"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRequest filters by exact match on the given fields, Key searches
+// action, path, actor name and detail
+type ListRequest struct {
+	models.PageInfo
+	ActorID    uint   `form:"actorID"`
+	UserID     uint   `form:"userID"`
+	Action     string `form:"action"`
+	TargetType string `form:"targetType"`
+	TargetID   string `form:"targetID"`
+	Result     string `form:"result"`
+}
+
+func (API) ListView(c *gin.Context) {
+	req := middlewares.GetBind[ListRequest](c)
+
+	list, count, _ := common.QueryList(models.AuditLogModel{
+		ActorID:    req.ActorID,
+		UserID:     req.UserID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Result:     req.Result,
+	}, common.QueryOption{
+		PageInfo: req.PageInfo,
+		Likes:    []string{"action", "path", "actor_name", "detail"},
+	})
+
+	response.OKWithList(c, list, count)
+}
@@ -0,0 +1,4 @@
+package debug
+
+type API struct {
+}
@@ -0,0 +1,69 @@
+package debug
+
+import (
+	"fast-gin/global"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+)
+
+type DBPoolStats struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"` // 0 is unlimited
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`         // Connections waited for
+	WaitDuration       int64 `json:"waitDuration"`      // Milliseconds waited in total
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`     // Closed by max_idle_conns
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"` // Closed by conn_max_idle_time
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"` // Closed by conn_max_lifetime
+}
+
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`     // Free connection found in the pool
+	Misses     uint32 `json:"misses"`   // No free connection in the pool
+	Timeouts   uint32 `json:"timeouts"` // Waits exceeding pool_timeout
+	TotalConns uint32 `json:"totalConns"`
+	IdleConns  uint32 `json:"idleConns"`
+	StaleConns uint32 `json:"staleConns"` // Removed from the pool
+}
+
+// PoolsResponse holds null for a backend which is not connected
+type PoolsResponse struct {
+	DB    *DBPoolStats    `json:"db"`
+	Redis *RedisPoolStats `json:"redis"`
+}
+
+func (API) PoolsView(c *gin.Context) {
+	var res PoolsResponse
+
+	if global.DB != nil {
+		if sqlDB, err := global.DB.DB(); err == nil {
+			stats := sqlDB.Stats()
+			res.DB = &DBPoolStats{
+				MaxOpenConnections: stats.MaxOpenConnections,
+				OpenConnections:    stats.OpenConnections,
+				InUse:              stats.InUse,
+				Idle:               stats.Idle,
+				WaitCount:          stats.WaitCount,
+				WaitDuration:       stats.WaitDuration.Milliseconds(),
+				MaxIdleClosed:      stats.MaxIdleClosed,
+				MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+				MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+			}
+		}
+	}
+
+	if global.Redis != nil {
+		stats := global.Redis.PoolStats()
+		res.Redis = &RedisPoolStats{
+			Hits:       stats.Hits,
+			Misses:     stats.Misses,
+			Timeouts:   stats.Timeouts,
+			TotalConns: stats.TotalConns,
+			IdleConns:  stats.IdleConns,
+			StaleConns: stats.StaleConns,
+		}
+	}
+
+	response.OKWithData(c, res)
+}
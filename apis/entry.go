@@ -1,9 +1,14 @@
 package apis
 
 import (
+	"fast-gin/apis/audit"
 	"fast-gin/apis/captcha"
+	"fast-gin/apis/debug"
 	"fast-gin/apis/image"
+	"fast-gin/apis/jwks"
+	"fast-gin/apis/me"
 	"fast-gin/apis/probe"
+	"fast-gin/apis/role"
 	"fast-gin/apis/user"
 )
 
@@ -12,6 +17,11 @@ type APIs struct {
 	ImageAPI   image.API
 	CaptchaAPI captcha.API
 	ProbeAPI   probe.API
+	JWKSAPI    jwks.API
+	RoleAPI    role.API
+	MeAPI      me.API
+	AuditAPI   audit.API
+	DebugAPI   debug.API
 }
 
 var Apis = new(APIs)
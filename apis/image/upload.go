@@ -2,6 +2,7 @@ package image
 
 import (
 	"fast-gin/global"
+	"fast-gin/middlewares"
 	"fast-gin/utils/md5"
 	"fast-gin/utils/response"
 	"fmt"
@@ -20,6 +21,7 @@ var whiteList = map[string]struct{}{
 }
 
 func (API) UploadView(c *gin.Context) {
+	cfg := global.Config()
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		response.FailWithMsg(c, "Please select an image")
@@ -27,8 +29,8 @@ func (API) UploadView(c *gin.Context) {
 	}
 
 	// Size
-	if fileHeader.Size > global.Config.Upload.Size*1024*1024 {
-		response.FailWithMsg(c, "File size too big (>2MB)")
+	if fileHeader.Size > cfg.Upload.Size*1024*1024 {
+		response.FailWithMsg(c, fmt.Sprintf("File size too big (>%dMB)", cfg.Upload.Size))
 		return
 	}
 
@@ -40,7 +42,7 @@ func (API) UploadView(c *gin.Context) {
 	}
 
 	// De-duplicate
-	dir := path.Join("uploads", global.Config.Upload.Dir, fileHeader.Filename)
+	dir := path.Join("uploads", cfg.Upload.Dir, fileHeader.Filename)
 
 	_, err = os.Stat(dir)
 	if !os.IsNotExist(err) {
@@ -66,7 +68,7 @@ func (API) UploadView(c *gin.Context) {
 
 		// TODO: random string
 		newFilename := fmt.Sprintf("%s_%s.%s", strings.TrimSuffix(fileHeader.Filename, ext), "random_string", ext)
-		dir = path.Join("uploads", global.Config.Upload.Dir, newFilename)
+		dir = path.Join("uploads", cfg.Upload.Dir, newFilename)
 	}
 
 	err = c.SaveUploadedFile(fileHeader, dir)
@@ -74,6 +76,7 @@ func (API) UploadView(c *gin.Context) {
 		response.FailWithMsg(c, err.Error())
 		return
 	}
+	middlewares.AuditTarget(c, "image", dir)
 
 	response.OK(c, gin.H{}, "Upload successfully")
 }
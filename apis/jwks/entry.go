@@ -0,0 +1,4 @@
+package jwks
+
+type API struct {
+}
@@ -0,0 +1,22 @@
+package jwks
+
+import (
+	"fast-gin/utils/jwts"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// JWKSView publishes the public verification keys, the body is a bare JWK
+// Set rather than the response envelope so that standard JWT libraries can
+// consume it directly
+func (API) JWKSView(c *gin.Context) {
+	keySet, err := jwts.PublicJWKS()
+	if err != nil {
+		logrus.Errorf("Failed to build JWKS: %v", err)
+		c.JSON(http.StatusServiceUnavailable, jwts.JWKS{Keys: []jwts.JWK{}})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keySet)
+}
@@ -0,0 +1,85 @@
+package me
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_apikey"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type APIKeyCreateRequest struct {
+	Name      string   `json:"name" binding:"required,max=64" label:"name"`
+	Scopes    []string `json:"scopes" binding:"required,min=1" label:"scopes"`
+	ExpiresIn int      `json:"expiresIn" binding:"min=0" label:"expiresIn"` // Days, 0 never expires
+}
+
+type APIKeyCreateResponse struct {
+	models.APIKeyModel
+	Key string `json:"key"` // Shown only once
+}
+
+type APIKeyRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+func (API) APIKeyListView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+
+	list, err := svc_apikey.List(claims.UserID)
+	if err != nil {
+		logrus.Errorf("Failed to list API keys: %v", err)
+		response.FailWithMsg(c, "Failed to list API keys")
+		return
+	}
+
+	response.OKWithList(c, list, int64(len(list)))
+}
+
+func (API) APIKeyCreateView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[APIKeyCreateRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresIn)
+		expiresAt = &t
+	}
+	key, plain, err := svc_apikey.Create(user, req.Name, req.Scopes, expiresAt)
+	if errors.Is(err, svc_apikey.ErrScopeInvalid) {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to create API key: %v", err)
+		response.FailWithMsg(c, "Failed to create API key")
+		return
+	}
+
+	middlewares.AuditTarget(c, "api_key", key.ID)
+	middlewares.AuditDiff(c, nil, key)
+	response.OKWithData(c, APIKeyCreateResponse{APIKeyModel: key, Key: plain})
+}
+
+func (API) APIKeyRevokeView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	uri := middlewares.GetBind[APIKeyRequest](c)
+
+	if !svc_apikey.Revoke(claims.UserID, uri.ID) {
+		response.FailWithMsg(c, "API key does not exist")
+		return
+	}
+
+	response.OKWithMsg(c, "API key revoked successfully")
+}
@@ -0,0 +1,4 @@
+package me
+
+type API struct {
+}
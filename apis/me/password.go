@@ -0,0 +1,56 @@
+package me
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" binding:"required" label:"oldPwd"`
+	Password    string `json:"password" binding:"required" label:"pwd"`
+}
+
+func (API) ChangePasswordView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[ChangePasswordRequest](c)
+
+	if err := pwd.CheckPolicy(req.Password); err != nil {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	if !pwd.Validate(user.Password, req.OldPassword) {
+		response.FailWithMsg(c, "Old password is incorrect")
+		return
+	}
+
+	encryptedPassword, err := pwd.Encrypt(req.Password)
+	if err != nil {
+		response.FailWithMsg(c, "Failed to change password")
+		return
+	}
+	err = global.DB.Model(&user).Update("password", encryptedPassword).Error
+	if err != nil {
+		logrus.Errorf("Failed to change password: %s", err)
+		response.FailWithMsg(c, "Failed to change password")
+		return
+	}
+
+	// Sign out every other device
+	svc_redis.RevokeUserSessions(user.ID, claims.SessionID)
+
+	response.OKWithMsg(c, "Password changed successfully")
+}
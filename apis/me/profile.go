@@ -0,0 +1,48 @@
+package me
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (API) ProfileView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	response.OKWithData(c, user)
+}
+
+type UpdateProfileRequest struct {
+	Nickname string `json:"nickname" binding:"max=32" label:"nickname"`
+}
+
+func (API) UpdateProfileView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[UpdateProfileRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	err = global.DB.Model(&user).Update("nickname", req.Nickname).Error
+	if err != nil {
+		logrus.Errorf("Failed to update profile: %s", err)
+		response.FailWithMsg(c, "Failed to update profile")
+		return
+	}
+
+	response.OKWithData(c, user)
+}
@@ -0,0 +1,60 @@
+package me
+
+import (
+	"fast-gin/middlewares"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	Current    bool      `json:"current"`
+}
+
+type SessionRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+func (API) SessionListView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+
+	sessions, err := svc_redis.ListSessions(claims.UserID)
+	if err != nil {
+		logrus.Errorf("Failed to list sessions: %v", err)
+		response.FailWithMsg(c, "Failed to list sessions")
+		return
+	}
+
+	list := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, SessionResponse{
+			ID:         session.Family,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.Family == claims.SessionID,
+		})
+	}
+
+	response.OKWithList(c, list, int64(len(list)))
+}
+
+func (API) SessionRevokeView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	uri := middlewares.GetBind[SessionRequest](c)
+
+	if !svc_redis.RevokeSession(claims.UserID, uri.ID) {
+		response.FailWithMsg(c, "Session does not exist")
+		return
+	}
+
+	response.OKWithMsg(c, "Session revoked successfully")
+}
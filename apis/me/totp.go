@@ -0,0 +1,199 @@
+package me
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_mfa"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"fast-gin/utils/totp"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type TOTPSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qrCode"` // Base64 PNG data URI
+}
+
+func (API) TOTPSetupView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	if user.TOTPEnabled {
+		response.FailWithMsg(c, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logrus.Errorf("Failed to generate TOTP secret: %v", err)
+		response.FailWithMsg(c, "Failed to set up two-factor authentication")
+		return
+	}
+	uri := totp.URI(global.Config().JWT.Issuer, user.Username, secret)
+	qrCode, err := totp.QRCode(uri)
+	if err != nil {
+		logrus.Errorf("Failed to generate TOTP QR code: %v", err)
+		response.FailWithMsg(c, "Failed to set up two-factor authentication")
+		return
+	}
+
+	// Confirmed by TOTPEnableView
+	err = svc_mfa.SetPendingSecret(user.ID, secret)
+	if err != nil {
+		logrus.Errorf("Failed to store pending TOTP secret: %v", err)
+		response.FailWithMsg(c, "Failed to set up two-factor authentication")
+		return
+	}
+
+	response.OKWithData(c, TOTPSetupResponse{
+		Secret: secret,
+		URI:    uri,
+		QRCode: qrCode,
+	})
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required" label:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func (API) TOTPEnableView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[TOTPCodeRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	if user.TOTPEnabled {
+		response.FailWithMsg(c, "Two-factor authentication is already enabled")
+		return
+	}
+
+	// 1. Code must match the pending secret
+	secret, err := svc_mfa.TakePendingSecret(user.ID)
+	if err != nil {
+		if !errors.Is(err, svc_mfa.ErrNoPendingSecret) {
+			logrus.Errorf("Failed to get pending TOTP secret: %v", err)
+		}
+		response.FailWithMsg(c, "Please set up two-factor authentication first")
+		return
+	}
+	if !svc_mfa.VerifyTOTP(user.ID, secret, req.Code) {
+		// Allow another attempt with the same secret
+		_ = svc_mfa.SetPendingSecret(user.ID, secret)
+		response.FailWithMsg(c, "Invalid code")
+		return
+	}
+
+	// 2. Persist
+	err = global.DB.Model(&user).Updates(map[string]any{
+		"totp_secret":  secret,
+		"totp_enabled": true,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Failed to enable TOTP: %s", err)
+		response.FailWithMsg(c, "Failed to enable two-factor authentication")
+		return
+	}
+
+	// 3. Recovery codes are shown only once
+	codes, err := svc_mfa.GenerateRecoveryCodes(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to generate recovery codes: %s", err)
+		response.FailWithMsg(c, "Two-factor authentication enabled, but failed to generate recovery codes")
+		return
+	}
+
+	response.OKWithData(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type TOTPDisableRequest struct {
+	Password string `json:"password" binding:"required" label:"pwd"`
+	Code     string `json:"code" binding:"required" label:"code"` // TOTP or recovery code
+}
+
+func (API) TOTPDisableView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[TOTPDisableRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	if !user.TOTPEnabled {
+		response.FailWithMsg(c, "Two-factor authentication is not enabled")
+		return
+	}
+
+	if !pwd.Validate(user.Password, req.Password) {
+		response.FailWithMsg(c, "Password is incorrect")
+		return
+	}
+	if !svc_mfa.VerifyTOTP(user.ID, user.TOTPSecret, req.Code) && !svc_mfa.UseRecoveryCode(user.ID, req.Code) {
+		response.FailWithMsg(c, "Invalid code")
+		return
+	}
+
+	err = global.DB.Model(&user).Updates(map[string]any{
+		"totp_secret":  "",
+		"totp_enabled": false,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Failed to disable TOTP: %s", err)
+		response.FailWithMsg(c, "Failed to disable two-factor authentication")
+		return
+	}
+	err = svc_mfa.RemoveRecoveryCodes(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to remove recovery codes: %s", err)
+	}
+
+	response.OKWithMsg(c, "Two-factor authentication disabled")
+}
+
+func (API) RecoveryCodesView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	req := middlewares.GetBind[TOTPCodeRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	if !user.TOTPEnabled {
+		response.FailWithMsg(c, "Two-factor authentication is not enabled")
+		return
+	}
+	if !svc_mfa.VerifyTOTP(user.ID, user.TOTPSecret, req.Code) {
+		response.FailWithMsg(c, "Invalid code")
+		return
+	}
+
+	codes, err := svc_mfa.GenerateRecoveryCodes(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to generate recovery codes: %s", err)
+		response.FailWithMsg(c, "Failed to generate recovery codes")
+		return
+	}
+
+	response.OKWithData(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
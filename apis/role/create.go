@@ -0,0 +1,59 @@
+package role
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var errTooManyRoles = errors.New("role ID does not fit in an int8")
+
+type CreateRequest struct {
+	Name        string `json:"name" binding:"required,max=32" label:"name"`
+	Description string `json:"description" binding:"max=128" label:"description"`
+}
+
+func (API) CreateView(c *gin.Context) {
+	req := middlewares.GetBind[CreateRequest](c)
+
+	var role models.RoleModel
+	err := global.DB.Take(&role, "name = ?", req.Name).Error
+	if err == nil {
+		response.FailWithMsg(c, "Role already exists")
+		return
+	}
+
+	role = models.RoleModel{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	// UserModel.RoleID is an int8, IDs are auto incremented so check the one
+	// given to the role rather than the number of roles
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		if _, ok := role.RoleID(); !ok {
+			return errTooManyRoles
+		}
+		return nil
+	})
+	if errors.Is(err, errTooManyRoles) {
+		response.FailWithMsg(c, "Too many roles")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to create role: %v", err)
+		response.FailWithMsg(c, "Failed to create role")
+		return
+	}
+	middlewares.AuditTarget(c, "role", role.ID)
+	middlewares.AuditDiff(c, nil, role)
+
+	response.OKWithData(c, role)
+}
@@ -0,0 +1,4 @@
+package role
+
+type API struct {
+}
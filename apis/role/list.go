@@ -0,0 +1,34 @@
+package role
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/common"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (API) ListView(c *gin.Context) {
+	req := middlewares.GetBind[models.PageInfo](c)
+
+	list, count, _ := common.QueryList(models.RoleModel{}, common.QueryOption{
+		PageInfo: req,
+		Likes:    []string{"name", "description"},
+	})
+
+	response.OKWithList(c, list, count)
+}
+
+func (API) PermissionListView(c *gin.Context) {
+	var list []models.PermissionModel
+	err := global.DB.Order("code").Find(&list).Error
+	if err != nil {
+		logrus.Errorf("Failed to list permissions: %v", err)
+		response.FailWithMsg(c, "Failed to list permissions")
+		return
+	}
+
+	response.OKWithList(c, list, int64(len(list)))
+}
@@ -0,0 +1,57 @@
+package role
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_rbac"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func (API) RemoveView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var role models.RoleModel
+	err := global.DB.Take(&role, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+
+	roleID, ok := role.RoleID()
+	if !ok {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+	if roleID == models.AdminRoleID || roleID == models.UserRoleID {
+		response.FailWithMsg(c, "Built-in role cannot be removed")
+		return
+	}
+
+	var count int64
+	global.DB.Model(&models.UserModel{}).Where("role_id = ?", roleID).Count(&count)
+	if count > 0 {
+		response.FailWithMsg(c, "Role is still assigned to users")
+		return
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermissionModel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		logrus.Errorf("Failed to remove role: %v", err)
+		response.FailWithMsg(c, "Failed to remove role")
+		return
+	}
+	svc_rbac.InvalidateRole(roleID)
+	middlewares.AuditDiff(c, role, nil)
+
+	response.OKWithMsg(c, "Role removed successfully")
+}
@@ -0,0 +1,113 @@
+package role
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_rbac"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type UpdateRequest struct {
+	Name        string `json:"name" binding:"required,max=32" label:"name"`
+	Description string `json:"description" binding:"max=128" label:"description"`
+}
+
+func (API) UpdateView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[UpdateRequest](c)
+
+	var role models.RoleModel
+	err := global.DB.Take(&role, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+
+	var other models.RoleModel
+	err = global.DB.Take(&other, "name = ? AND id <> ?", req.Name, role.ID).Error
+	if err == nil {
+		response.FailWithMsg(c, "Role already exists")
+		return
+	}
+
+	before := role
+	err = global.DB.Model(&role).Updates(map[string]any{
+		"name":        req.Name,
+		"description": req.Description,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Failed to update role: %v", err)
+		response.FailWithMsg(c, "Failed to update role")
+		return
+	}
+	middlewares.AuditDiff(c, before, role)
+
+	response.OKWithData(c, role)
+}
+
+type SetPermissionsRequest struct {
+	Permissions []string `json:"permissions" label:"permissions"`
+}
+
+func (API) SetPermissionsView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[SetPermissionsRequest](c)
+
+	var role models.RoleModel
+	err := global.DB.Take(&role, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+
+	roleID, ok := role.RoleID()
+	if !ok {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+
+	var perms []models.PermissionModel
+	if len(req.Permissions) > 0 {
+		global.DB.Find(&perms, "code IN ?", req.Permissions)
+	}
+	if len(perms) != len(req.Permissions) {
+		response.FailWithMsg(c, "Permission does not exist")
+		return
+	}
+
+	before, err := svc_rbac.GetPermissions(roleID)
+	if err != nil {
+		logrus.Errorf("Failed to get permissions of role: %v", err)
+	}
+
+	// Replace the whole permission set of role
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermissionModel{}).Error
+		if err != nil {
+			return err
+		}
+		for _, perm := range perms {
+			err = tx.Create(&models.RolePermissionModel{
+				RoleID:       role.ID,
+				PermissionID: perm.ID,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to set permissions of role: %v", err)
+		response.FailWithMsg(c, "Failed to set permissions")
+		return
+	}
+	svc_rbac.InvalidateRole(roleID)
+	middlewares.AuditDiff(c, gin.H{"permissions": before}, gin.H{"permissions": req.Permissions})
+
+	response.OKWithMsg(c, "Permissions updated successfully")
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"errors"
+	"fast-gin/apis/me"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_apikey"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type APIKeyRequest struct {
+	ID    uint `uri:"id" binding:"required"`
+	KeyID uint `uri:"keyID" binding:"required"`
+}
+
+func (API) APIKeyListView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	list, err := svc_apikey.List(uri.ID)
+	if err != nil {
+		logrus.Errorf("Failed to list API keys: %v", err)
+		response.FailWithMsg(c, "Failed to list API keys")
+		return
+	}
+
+	response.OKWithList(c, list, int64(len(list)))
+}
+
+// APIKeyCreateView issues a key on behalf of a user, e.g. a service account
+func (API) APIKeyCreateView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[me.APIKeyCreateRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresIn)
+		expiresAt = &t
+	}
+	key, plain, err := svc_apikey.Create(user, req.Name, req.Scopes, expiresAt)
+	if errors.Is(err, svc_apikey.ErrScopeInvalid) {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to create API key: %v", err)
+		response.FailWithMsg(c, "Failed to create API key")
+		return
+	}
+
+	logrus.Infof("API key [%s] created for user [%s]", key.Name, user.Username)
+	middlewares.AuditTarget(c, "api_key", key.ID)
+	middlewares.AuditDiff(c, nil, key)
+	response.OKWithData(c, me.APIKeyCreateResponse{APIKeyModel: key, Key: plain})
+}
+
+func (API) APIKeyRevokeView(c *gin.Context) {
+	uri := middlewares.GetBind[APIKeyRequest](c)
+	middlewares.AuditTarget(c, "api_key", uri.KeyID)
+
+	if !svc_apikey.Revoke(uri.ID, uri.KeyID) {
+		response.FailWithMsg(c, "API key does not exist")
+		return
+	}
+
+	response.OKWithMsg(c, "API key revoked successfully")
+}
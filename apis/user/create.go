@@ -0,0 +1,76 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+type CreateRequest struct {
+	Username string `json:"username" binding:"required,max=16" label:"uname"`
+	Nickname string `json:"nickname" binding:"max=32" label:"nickname"`
+	Email    string `json:"email" binding:"omitempty,email,max=128" label:"email"`
+	Password string `json:"password" binding:"required" label:"pwd"`
+	RoleID   int8   `json:"roleID" binding:"required" label:"roleID"`
+}
+
+func (API) CreateView(c *gin.Context) {
+	req := middlewares.GetBind[CreateRequest](c)
+
+	if err := pwd.CheckPolicy(req.Password); err != nil {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+
+	var user models.UserModel
+	err := global.DB.Take(&user, "username = ?", req.Username).Error
+	if err == nil {
+		response.FailWithMsg(c, "User already exists")
+		return
+	}
+	if req.Email != "" {
+		err = global.DB.Take(&user, "email = ?", strings.ToLower(req.Email)).Error
+		if err == nil {
+			response.FailWithMsg(c, "Email already exists")
+			return
+		}
+	}
+
+	var role models.RoleModel
+	err = global.DB.Take(&role, req.RoleID).Error
+	if err != nil {
+		response.FailWithMsg(c, "Role does not exist")
+		return
+	}
+
+	encryptedPassword, err := pwd.Encrypt(req.Password)
+	if err != nil {
+		response.FailWithMsg(c, "Failed to create user")
+		return
+	}
+
+	user = models.UserModel{
+		Username: req.Username,
+		Nickname: req.Nickname,
+		Email:    strings.ToLower(req.Email),
+		Password: encryptedPassword,
+		RoleID:   req.RoleID,
+		Status:   models.UserStatusActive,
+	}
+	err = global.DB.Create(&user).Error
+	if err != nil {
+		logrus.Errorf("Failed to create user: %s", err)
+		response.FailWithMsg(c, "Failed to create user")
+		return
+	}
+	logrus.Infof("Create user [%s] successfully", user.Username)
+	middlewares.AuditTarget(c, "user", user.ID)
+	middlewares.AuditDiff(c, nil, user)
+
+	response.OKWithData(c, user)
+}
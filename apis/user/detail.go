@@ -0,0 +1,22 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (API) DetailView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	response.OKWithData(c, user)
+}
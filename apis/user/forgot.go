@@ -0,0 +1,145 @@
+package user
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_mail"
+	"fast-gin/service/svc_redis"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	resetPasswordExpire = 30 * time.Minute
+	resetPasswordWindow = time.Hour
+	resetPerUserLimit   = 3  // Reset emails per account per window
+	resetPerIPLimit     = 10 // Reset requests per IP per window
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" label:"email"`
+}
+
+func (API) ForgotPasswordView(c *gin.Context) {
+	req := middlewares.GetBind[ForgotPasswordRequest](c)
+
+	if global.Redis == nil {
+		response.FailWithMsg(c, "Password reset is not available")
+		return
+	}
+
+	// 1. Rate limit per IP
+	ok, err := svc_redis.Allow(fmt.Sprintf("pwd_reset_ip_%s", c.ClientIP()), resetPerIPLimit, resetPasswordWindow)
+	if err != nil {
+		logrus.Errorf("Failed to rate limit password reset: %v", err)
+	}
+	if !ok {
+		response.FailWithMsg(c, "Too many requests")
+		return
+	}
+
+	// Same answer whether the account exists or not
+	const msg = "If the account exists, a password reset email has been sent"
+
+	// 2. Get user from DB
+	var user models.UserModel
+	err = global.DB.Take(&user, "email = ?", strings.ToLower(req.Email)).Error
+	if err != nil || user.Status == models.UserStatusDisabled {
+		response.OKWithMsg(c, msg)
+		return
+	}
+
+	// 3. Rate limit per account
+	ok, err = svc_redis.Allow(fmt.Sprintf("pwd_reset_rate_%d", user.ID), resetPerUserLimit, resetPasswordWindow)
+	if err != nil {
+		logrus.Errorf("Failed to rate limit password reset: %v", err)
+	}
+	if !ok {
+		logrus.Warnf("Too many password reset requests for user [%s]", user.Username)
+		response.OKWithMsg(c, msg)
+		return
+	}
+
+	// 4. Issue token and send it
+	token, err := svc_redis.IssuePasswordResetToken(user.ID, resetPasswordExpire)
+	if err != nil {
+		logrus.Errorf("Failed to issue password reset token: %v", err)
+		response.OKWithMsg(c, msg)
+		return
+	}
+	err = svc_mail.Send(svc_mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the token below within %d minutes to reset your password, it can be used only once:\n\n%s\n\nIf you did not ask for it, please ignore this email.\n",
+			user.Username, int(resetPasswordExpire.Minutes()), token),
+	})
+	if err != nil {
+		logrus.Errorf("Failed to send password reset email: %v", err)
+	}
+
+	response.OKWithMsg(c, msg)
+}
+
+type ResetForgottenPasswordRequest struct {
+	Token    string `json:"token" binding:"required" label:"token"`
+	Password string `json:"password" binding:"required" label:"pwd"`
+}
+
+func (API) ResetForgottenPasswordView(c *gin.Context) {
+	req := middlewares.GetBind[ResetForgottenPasswordRequest](c)
+
+	if err := pwd.CheckPolicy(req.Password); err != nil {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+
+	// 1. Consume token
+	userID, err := svc_redis.ConsumePasswordResetToken(req.Token)
+	if err != nil {
+		if !errors.Is(err, svc_redis.ErrResetTokenInvalid) {
+			logrus.Errorf("Failed to consume password reset token: %v", err)
+		}
+		response.FailWithMsg(c, "Invalid or expired token")
+		return
+	}
+
+	var user models.UserModel
+	err = global.DB.Take(&user, userID).Error
+	if err != nil || user.Status == models.UserStatusDisabled {
+		response.FailWithMsg(c, "Invalid or expired token")
+		return
+	}
+
+	// 2. Update password, the email is proven to be owned by now
+	encryptedPassword, err := pwd.Encrypt(req.Password)
+	if err != nil {
+		response.FailWithMsg(c, "Failed to reset password")
+		return
+	}
+	err = global.DB.Model(&user).Updates(map[string]any{
+		"password": encryptedPassword,
+		"status":   models.UserStatusActive,
+	}).Error
+	if err != nil {
+		logrus.Errorf("Failed to reset password: %s", err)
+		response.FailWithMsg(c, "Failed to reset password")
+		return
+	}
+
+	// 3. Sign out everywhere
+	err = svc_user.RevokeTokens(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke tokens: %s", err)
+	}
+	logrus.Infof("Password of user [%s] reset by email", user.Username)
+
+	response.OKWithMsg(c, "Password reset successfully")
+}
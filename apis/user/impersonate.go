@@ -0,0 +1,98 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_audit"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255" label:"reason"`
+}
+
+type ImpersonateResponse struct {
+	AccessToken  string `json:"accessToken"`
+	ExpiresIn    int    `json:"expiresIn"` // Seconds
+	Impersonated bool   `json:"impersonated"`
+}
+
+// ImpersonateView issues a short-lived token acting as another user, for
+// support staff reproducing issues. Admins cannot be impersonated.
+func (API) ImpersonateView(c *gin.Context) {
+	claims := middlewares.GetClaimsFrom(c)
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[ImpersonateRequest](c)
+
+	var actor models.UserModel
+	err := global.DB.Take(&actor, claims.UserID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	var user models.UserModel
+	err = global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	if user.ID == actor.ID {
+		response.FailWithMsg(c, "Cannot impersonate yourself")
+		return
+	}
+	if user.RoleID == models.AdminRoleID {
+		response.FailWithMsg(c, "Cannot impersonate an admin")
+		return
+	}
+	if user.Status != models.UserStatusActive {
+		response.FailWithMsg(c, "User is not active")
+		return
+	}
+
+	expire := time.Duration(global.Config().JWT.ImpersonateExpire) * time.Minute
+	if expire <= 0 {
+		expire = 15 * time.Minute
+	}
+	token, err := jwts.GenerateImpersonationJWT(jwts.ClaimMeta{
+		UserID:  user.ID,
+		RoleID:  user.RoleID,
+		Version: user.TokenVersion,
+		Actor: &jwts.Actor{
+			UserID:   actor.ID,
+			Username: actor.Username,
+			Version:  actor.TokenVersion,
+		},
+	}, expire)
+	if err != nil {
+		logrus.Errorf("Failed to generate impersonation token: %v", err)
+		response.FailWithMsg(c, "Failed to impersonate user")
+		return
+	}
+
+	svc_audit.Record(svc_audit.FromRequest(c, models.AuditLogModel{
+		ActorID:    actor.ID,
+		ActorName:  actor.Username,
+		UserID:     actor.ID,
+		Action:     svc_audit.ActionImpersonationStart,
+		TargetType: "user",
+		TargetID:   fmt.Sprint(user.ID),
+		Result:     models.AuditResultSuccess,
+		Status:     200,
+		Detail:     req.Reason,
+	}))
+	logrus.Warnf("User [%s] impersonates user [%s]: %s", actor.Username, user.Username, req.Reason)
+
+	c.Header(middlewares.ImpersonatedByHeader, fmt.Sprintf("%s (%d)", actor.Username, actor.ID))
+	response.OKWithData(c, ImpersonateResponse{
+		AccessToken:  token,
+		ExpiresIn:    int(expire.Seconds()),
+		Impersonated: true,
+	})
+}
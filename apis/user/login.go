@@ -4,12 +4,16 @@ import (
 	"fast-gin/global"
 	"fast-gin/middlewares"
 	"fast-gin/models"
+	"fast-gin/service/svc_oidc"
+	"fast-gin/service/svc_redis"
 	"fast-gin/utils/captcha"
 	"fast-gin/utils/jwts"
 	"fast-gin/utils/pwd"
 	"fast-gin/utils/response"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
 type LoginRequest struct {
@@ -19,11 +23,26 @@ type LoginRequest struct {
 	CaptchaAns string `json:"captchaAns"`
 }
 
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn"` // Seconds
+}
+
 func (API) LoginView(c *gin.Context) {
 	req := middlewares.GetBind[LoginRequest](c)
 
-	// 1. Validate captcha
-	if global.Config.Site.Login.Captcha {
+	ip := c.ClientIP()
+	middlewares.AuditTarget(c, "user", req.Username)
+
+	// 1. Check lockout
+	if remaining, locked := svc_redis.LoginLocked(req.Username, ip); locked {
+		response.FailWithMsg(c, fmt.Sprintf("Too many failed attempts, please try again in %s", remaining.Round(time.Second)))
+		return
+	}
+
+	// 2. Validate captcha
+	if captchaRequired(req.Username, ip) {
 		if req.CaptchaID == "" || req.CaptchaAns == "" {
 			response.FailWithMsg(c, "Captcha is required")
 			return
@@ -34,30 +53,114 @@ func (API) LoginView(c *gin.Context) {
 		}
 	}
 
-	// 2. Get user from DB
+	// 3. Get user from DB
 	var user models.UserModel
 	err := global.DB.Take(&user, "username = ?", req.Username).Error
 	if err != nil {
+		svc_redis.RecordLoginFailure(req.Username, ip)
 		response.FailWithMsg(c, "Username or Password is incorrect")
 		return
 	}
+	middlewares.AuditActor(c, user.ID, user.Username)
 
-	// 3. Validate password
+	// 4. Validate password
 	if !pwd.Validate(user.Password, req.Password) {
+		svc_redis.RecordLoginFailure(req.Username, ip)
 		response.FailWithMsg(c, "Username or Password is incorrect")
 		return
 	}
+	svc_redis.ResetLoginFailures(req.Username)
+	if pwd.NeedsRehash(user.Password) {
+		rehash(user, req.Password)
+	}
+	if global.Config().OIDC.Enable && !global.Config().OIDC.PasswordLogin && svc_oidc.IsLinked(user.ID) {
+		response.FailWithMsg(c, "Please login with single sign-on")
+		return
+	}
 
-	// 4. Issue token
-	token, err := jwts.GenerateJWT(jwts.ClaimMeta{
-		UserID: user.ID,
-		RoleID: user.RoleID,
-	})
+	// 5. Check status
+	switch user.Status {
+	case models.UserStatusDisabled:
+		response.FailWithMsg(c, "User has been disabled")
+		return
+	case models.UserStatusUnverified:
+		response.FailWithMsg(c, "Please verify your email first")
+		return
+	}
+
+	// 6. Second factor, exchanged for tokens by LoginMFAView
+	if user.TOTPEnabled {
+		pendingMFA(c, user)
+		return
+	}
+
+	// 7. Issue token
+	res, err := issueTokens(c, user)
 	if err != nil {
 		logrus.Errorf("Failed to generate JWT token: %v", err)
 		response.FailWithMsg(c, "Failed to login")
+		return
 	}
 
-	response.OKWithData(c, token)
+	response.OKWithData(c, res)
+	return
+}
+
+// rehash upgrades the stored hash of user to the configured hasher and
+// parameters, the plain password is only known right after login
+func rehash(user models.UserModel, password string) {
+	encryptedPassword, err := pwd.Encrypt(password)
+	if err != nil {
+		return
+	}
+	err = global.DB.Model(&user).UpdateColumn("password", encryptedPassword).Error
+	if err != nil {
+		logrus.Errorf("Failed to rehash password of user [%s]: %s", user.Username, err)
+		return
+	}
+	logrus.Infof("Password of user [%s] rehashed", user.Username)
+}
+
+// captchaRequired reports whether the login has to solve a captcha, either
+// always or only after a few failed attempts
+func captchaRequired(username, ip string) bool {
+	cfg := global.Config().Site.Login
+	if !cfg.Captcha {
+		return false
+	}
+	if cfg.CaptchaAfter <= 0 {
+		return true
+	}
+	return svc_redis.LoginFailures(username, ip) >= int64(cfg.CaptchaAfter)
+}
+
+// issueTokens starts a new session with a refresh token when Redis is
+// available, and signs an access token bound to it
+func issueTokens(c *gin.Context, user models.UserModel) (res TokenResponse, err error) {
+	var session svc_redis.RefreshSession
+	if global.Redis != nil {
+		session, res.RefreshToken, err = svc_redis.IssueRefreshToken(svc_redis.RefreshSession{
+			UserID:    user.ID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		if err != nil {
+			return
+		}
+	} else {
+		logrus.Warnf("Redis is not available, no refresh token issued")
+	}
+
+	res.AccessToken, err = jwts.GenerateJWT(jwts.ClaimMeta{
+		UserID:    user.ID,
+		RoleID:    user.RoleID,
+		SessionID: session.Family,
+		Version:   user.TokenVersion,
+	})
+	if err != nil {
+		return
+	}
+	res.ExpiresIn = global.Config().JWT.Expire * 3600
+	err = middlewares.SetTokenCookies(c, res.AccessToken, res.RefreshToken)
 	return
 }
@@ -2,19 +2,42 @@ package user
 
 import (
 	"fast-gin/global"
+	"fast-gin/middlewares"
 	"fast-gin/service/svc_redis"
 	"fast-gin/utils/response"
 	"github.com/gin-gonic/gin"
 )
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 func (API) LogoutView(c *gin.Context) {
-	token := c.GetHeader("token")
+	token, _ := middlewares.GetToken(c)
+	middlewares.ClearTokenCookies(c)
 	if global.Redis == nil {
 		response.OKWithMsg(c, "Logout successfully")
 		return
 	}
 
 	svc_redis.Logout(token)
+
+	// Revoke the session the token belongs to
+	claims := middlewares.GetClaimsFrom(c)
+	if claims.SessionID != "" {
+		svc_redis.RevokeSession(claims.UserID, claims.SessionID)
+	}
+
+	// Body is optional, revoke the refresh token family when given
+	var req LogoutRequest
+	c.ShouldBindJSON(&req)
+	if req.RefreshToken == "" {
+		req.RefreshToken = middlewares.GetRefreshTokenCookie(c)
+	}
+	if req.RefreshToken != "" {
+		svc_redis.RevokeRefreshToken(req.RefreshToken)
+	}
+
 	response.OKWithMsg(c, "Logout successfully")
 	return
 }
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_mfa"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/response"
+	"fast-gin/utils/sha256"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	mfaAction      = "mfa"
+	mfaExpire      = 5 * time.Minute
+	mfaMaxAttempts = 5 // Per pending token
+)
+
+type MFAPendingResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+	ExpiresIn   int    `json:"expiresIn"` // Seconds
+}
+
+// pendingMFA answers the password step of a login for users with TOTP enabled
+func pendingMFA(c *gin.Context, user models.UserModel) {
+	token, err := jwts.GenerateActionJWT(mfaAction, user.ID, "", mfaExpire)
+	if err != nil {
+		logrus.Errorf("Failed to generate MFA token: %v", err)
+		response.FailWithMsg(c, "Failed to login")
+		return
+	}
+
+	response.OKWithData(c, MFAPendingResponse{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int(mfaExpire.Seconds()),
+	})
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfaToken" binding:"required" label:"mfaToken"`
+	Code         string `json:"code" label:"code"`
+	RecoveryCode string `json:"recoveryCode" label:"recoveryCode"`
+}
+
+func (API) LoginMFAView(c *gin.Context) {
+	req := middlewares.GetBind[LoginMFARequest](c)
+
+	// 1. Validate pending token
+	claims, err := jwts.ValidateActionJWT(req.MFAToken, mfaAction)
+	if err != nil {
+		response.FailWithMsg(c, "Invalid or expired MFA token, please login again")
+		return
+	}
+	tokenHash := sha256.GetSHA256(req.MFAToken)
+	usedKey := fmt.Sprintf("mfa_used_%s", tokenHash)
+	if svc_redis.IsUsed(usedKey) {
+		response.FailWithMsg(c, "Invalid or expired MFA token, please login again")
+		return
+	}
+	ok, err := svc_redis.Allow(fmt.Sprintf("mfa_attempt_%s", tokenHash), mfaMaxAttempts, mfaExpire)
+	if err != nil {
+		logrus.Errorf("Failed to rate limit MFA: %v", err)
+	}
+	if !ok {
+		response.FailWithMsg(c, "Too many attempts, please login again")
+		return
+	}
+
+	// 2. Get user from DB
+	var user models.UserModel
+	err = global.DB.Take(&user, claims.UserID).Error
+	middlewares.AuditActor(c, user.ID, user.Username)
+	middlewares.AuditTarget(c, "user", user.Username)
+	if err != nil || user.Status != models.UserStatusActive || !user.TOTPEnabled {
+		response.FailWithMsg(c, "Invalid or expired MFA token, please login again")
+		return
+	}
+
+	// 3. Validate second factor
+	switch {
+	case req.Code != "":
+		ok = svc_mfa.VerifyTOTP(user.ID, user.TOTPSecret, req.Code)
+	case req.RecoveryCode != "":
+		ok = svc_mfa.UseRecoveryCode(user.ID, req.RecoveryCode)
+		if ok {
+			logrus.Warnf("User [%s] logged in with a recovery code", user.Username)
+		}
+	default:
+		response.FailWithMsg(c, "Code or recovery code is required")
+		return
+	}
+	if !ok {
+		response.FailWithMsg(c, "Invalid code")
+		return
+	}
+
+	// 4. Pending token can be exchanged only once
+	if !svc_redis.MarkUsed(usedKey, mfaExpire) {
+		response.FailWithMsg(c, "Invalid or expired MFA token, please login again")
+		return
+	}
+
+	// 5. Issue token
+	res, err := issueTokens(c, user)
+	if err != nil {
+		logrus.Errorf("Failed to generate JWT token: %v", err)
+		response.FailWithMsg(c, "Failed to login")
+		return
+	}
+
+	response.OKWithData(c, res)
+}
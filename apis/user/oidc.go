@@ -0,0 +1,102 @@
+package user
+
+import (
+	"errors"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_oidc"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required" label:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// OIDCLoginView redirects to the identity provider
+func (API) OIDCLoginView(c *gin.Context) {
+	url, err := svc_oidc.Begin(c.Request.Context())
+	if errors.Is(err, svc_oidc.ErrDisabled) {
+		response.FailWithMsg(c, "Single sign-on is disabled")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to start OIDC login: %v", err)
+		response.FailWithMsg(c, "Failed to start single sign-on")
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// OIDCCallbackView is where the identity provider sends the user agent back
+// to, it answers like LoginView
+func (API) OIDCCallbackView(c *gin.Context) {
+	req := middlewares.GetBind[OIDCCallbackRequest](c)
+
+	// 1. Provider side errors, e.g. access_denied
+	if req.Error != "" {
+		logrus.Warnf("OIDC login failed at provider: %s %s", req.Error, req.ErrorDescription)
+		response.FailWithMsg(c, "Single sign-on failed")
+		return
+	}
+	if req.Code == "" {
+		response.FailWithMsg(c, "Code is required")
+		return
+	}
+
+	// 2. Exchange code and verify ID token
+	claims, err := svc_oidc.Finish(c.Request.Context(), req.State, req.Code)
+	if errors.Is(err, svc_oidc.ErrStateInvalid) {
+		response.FailWithMsg(c, "Invalid or expired login, please try again")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to finish OIDC login: %v", err)
+		response.FailWithMsg(c, "Single sign-on failed")
+		return
+	}
+
+	// 3. Link or provision user
+	user, err := svc_oidc.ResolveUser(claims)
+	if errors.Is(err, svc_oidc.ErrNotLinked) {
+		response.FailWithMsg(c, "No user is linked to this account")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to resolve OIDC user: %v", err)
+		response.FailWithMsg(c, "Single sign-on failed")
+		return
+	}
+
+	middlewares.AuditActor(c, user.ID, user.Username)
+	middlewares.AuditTarget(c, "user", user.Username)
+	middlewares.AuditDetail(c, "oidc")
+
+	// 4. Check status
+	if user.Status == models.UserStatusDisabled {
+		response.FailWithMsg(c, "User has been disabled")
+		return
+	}
+
+	// 5. Second factor, exchanged for tokens by LoginMFAView
+	if user.TOTPEnabled {
+		pendingMFA(c, user)
+		return
+	}
+
+	// 6. Issue token
+	res, err := issueTokens(c, user)
+	if err != nil {
+		logrus.Errorf("Failed to generate JWT token: %v", err)
+		response.FailWithMsg(c, "Failed to login")
+		return
+	}
+
+	response.OKWithData(c, res)
+}
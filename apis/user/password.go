@@ -0,0 +1,51 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required" label:"pwd"`
+}
+
+func (API) ResetPasswordView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[ResetPasswordRequest](c)
+
+	if err := pwd.CheckPolicy(req.Password); err != nil {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	encryptedPassword, err := pwd.Encrypt(req.Password)
+	if err != nil {
+		response.FailWithMsg(c, "Failed to reset password")
+		return
+	}
+	err = global.DB.Model(&user).Update("password", encryptedPassword).Error
+	if err != nil {
+		logrus.Errorf("Failed to reset password: %s", err)
+		response.FailWithMsg(c, "Failed to reset password")
+		return
+	}
+	err = svc_user.RevokeTokens(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke tokens: %s", err)
+	}
+
+	response.OKWithMsg(c, "Password reset successfully")
+}
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" label:"refreshToken"` // Read from cookie when empty
+}
+
+func (API) RefreshView(c *gin.Context) {
+	var req RefreshRequest
+	c.ShouldBindJSON(&req)
+	if req.RefreshToken == "" {
+		req.RefreshToken = middlewares.GetRefreshTokenCookie(c)
+		if req.RefreshToken != "" && !middlewares.CheckCSRF(c) {
+			response.FailWithMsg(c, "CSRF token mismatch")
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		response.FailWithMsg(c, "Refresh token is required")
+		return
+	}
+
+	// 1. Rotate refresh token
+	session, refreshToken, err := svc_redis.RotateRefreshToken(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, svc_redis.ErrRefreshTokenReused) {
+			response.FailWithMsg(c, "Refresh token has already been used, please login again")
+			return
+		}
+		if !errors.Is(err, svc_redis.ErrRefreshTokenInvalid) {
+			logrus.Errorf("Failed to rotate refresh token: %v", err)
+		}
+		response.FailWithMsg(c, "Invalid refresh token")
+		return
+	}
+
+	// 2. Reload user so the new access token carries the current role
+	var user models.UserModel
+	err = global.DB.Take(&user, session.UserID).Error
+	if err != nil || user.Status != models.UserStatusActive {
+		svc_redis.RevokeRefreshFamily(session.Family)
+		response.FailWithMsg(c, "Invalid refresh token")
+		return
+	}
+
+	// 3. Issue access token
+	accessToken, err := jwts.GenerateJWT(jwts.ClaimMeta{
+		UserID:    user.ID,
+		RoleID:    user.RoleID,
+		SessionID: session.Family,
+		Version:   user.TokenVersion,
+	})
+	if err != nil {
+		logrus.Errorf("Failed to generate JWT token: %v", err)
+		response.FailWithMsg(c, "Failed to refresh token")
+		return
+	}
+
+	err = middlewares.SetTokenCookies(c, accessToken, refreshToken)
+	if err != nil {
+		logrus.Errorf("Failed to set token cookies: %v", err)
+	}
+
+	response.OKWithData(c, TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    global.Config().JWT.Expire * 3600,
+	})
+}
@@ -0,0 +1,169 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_mail"
+	"fast-gin/utils/captcha"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/pwd"
+	"fast-gin/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	verifyEmailAction = "verify_email"
+	verifyEmailExpire = 24 * time.Hour
+)
+
+type RegisterRequest struct {
+	Username   string `json:"username" binding:"required,max=16" label:"uname"`
+	Password   string `json:"password" binding:"required" label:"pwd"`
+	Email      string `json:"email" binding:"required,email,max=128" label:"email"`
+	CaptchaID  string `json:"captchaID" binding:"required" label:"captchaID"`
+	CaptchaAns string `json:"captchaAns" binding:"required" label:"captchaAns"`
+}
+
+func (API) RegisterView(c *gin.Context) {
+	req := middlewares.GetBind[RegisterRequest](c)
+
+	if err := pwd.CheckPolicy(req.Password); err != nil {
+		response.FailWithMsg(c, err.Error())
+		return
+	}
+
+	if !global.Config().Site.Register {
+		response.FailWithMsg(c, "Registration is disabled")
+		return
+	}
+
+	// 1. Validate captcha
+	if !captcha.CaptchaStore.Verify(req.CaptchaID, req.CaptchaAns, true) {
+		response.FailWithMsg(c, "Failed to validate captcha")
+		return
+	}
+
+	// 2. Username and email must be unique
+	email := strings.ToLower(req.Email)
+	var user models.UserModel
+	err := global.DB.Take(&user, "username = ? OR email = ?", req.Username, email).Error
+	if err == nil {
+		response.FailWithMsg(c, "Username or email already exists")
+		return
+	}
+
+	// 3. Persist as unverified
+	encryptedPassword, err := pwd.Encrypt(req.Password)
+	if err != nil {
+		response.FailWithMsg(c, "Failed to register")
+		return
+	}
+	user = models.UserModel{
+		Username: req.Username,
+		Email:    email,
+		Password: encryptedPassword,
+		RoleID:   models.UserRoleID,
+		Status:   models.UserStatusUnverified,
+	}
+	err = global.DB.Create(&user).Error
+	if err != nil {
+		logrus.Errorf("Failed to register user: %s", err)
+		response.FailWithMsg(c, "Failed to register")
+		return
+	}
+	logrus.Infof("Register user [%s] successfully", user.Username)
+
+	// 4. Send verification email, the user can ask to resend it on failure
+	err = sendVerifyEmail(user)
+	if err != nil {
+		logrus.Errorf("Failed to send verification email: %v", err)
+		response.OKWithMsg(c, "Registered successfully, but the verification email could not be sent")
+		return
+	}
+
+	response.OKWithMsg(c, "Registered successfully, please check your email")
+}
+
+type VerifyEmailRequest struct {
+	Token string `form:"token" binding:"required" label:"token"`
+}
+
+func (API) VerifyEmailView(c *gin.Context) {
+	req := middlewares.GetBind[VerifyEmailRequest](c)
+
+	claims, err := jwts.ValidateActionJWT(req.Token, verifyEmailAction)
+	if err != nil {
+		response.FailWithMsg(c, "Invalid or expired verification link")
+		return
+	}
+
+	var user models.UserModel
+	err = global.DB.Take(&user, claims.UserID).Error
+	if err != nil || user.Email != claims.Data {
+		response.FailWithMsg(c, "Invalid or expired verification link")
+		return
+	}
+	if user.Status != models.UserStatusUnverified {
+		response.OKWithMsg(c, "Email already verified")
+		return
+	}
+
+	err = global.DB.Model(&user).Update("status", models.UserStatusActive).Error
+	if err != nil {
+		logrus.Errorf("Failed to verify email: %s", err)
+		response.FailWithMsg(c, "Failed to verify email")
+		return
+	}
+
+	response.OKWithMsg(c, "Email verified successfully")
+}
+
+type ResendVerifyEmailRequest struct {
+	Email      string `json:"email" binding:"required,email" label:"email"`
+	CaptchaID  string `json:"captchaID" binding:"required" label:"captchaID"`
+	CaptchaAns string `json:"captchaAns" binding:"required" label:"captchaAns"`
+}
+
+func (API) ResendVerifyEmailView(c *gin.Context) {
+	req := middlewares.GetBind[ResendVerifyEmailRequest](c)
+
+	if !captcha.CaptchaStore.Verify(req.CaptchaID, req.CaptchaAns, true) {
+		response.FailWithMsg(c, "Failed to validate captcha")
+		return
+	}
+
+	// Same answer whether the account exists or not
+	var user models.UserModel
+	err := global.DB.Take(&user, "email = ? AND status = ?", strings.ToLower(req.Email), models.UserStatusUnverified).Error
+	if err == nil {
+		err = sendVerifyEmail(user)
+		if err != nil {
+			logrus.Errorf("Failed to send verification email: %v", err)
+		}
+	}
+
+	response.OKWithMsg(c, "If the account exists, a verification email has been sent")
+}
+
+func sendVerifyEmail(user models.UserModel) error {
+	token, err := jwts.GenerateActionJWT(verifyEmailAction, user.ID, user.Email, verifyEmailExpire)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/v1/users/verify?token=%s",
+		strings.TrimSuffix(global.Config().Site.URL, "/"),
+		url.QueryEscape(token),
+	)
+	return svc_mail.Send(svc_mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease open the link below within %d hours to verify your email:\n\n%s\n",
+			user.Username, int(verifyEmailExpire.Hours()), link),
+	})
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (API) RemoveView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	if svc_user.IsLastAdmin(user) {
+		response.FailWithMsg(c, "Cannot delete the last admin")
+		return
+	}
+
+	err = svc_user.RevokeTokens(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke tokens: %s", err)
+	}
+	err = global.DB.Delete(&user).Error
+	if err != nil {
+		logrus.Errorf("Failed to delete user: %s", err)
+		response.FailWithMsg(c, "Failed to delete user")
+		return
+	}
+	logrus.Infof("Delete user [%s] successfully", user.Username)
+	middlewares.AuditDiff(c, user, nil)
+
+	response.OKWithMsg(c, "User deleted successfully")
+}
@@ -0,0 +1,32 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RevokeView signs a user out everywhere
+func (API) RevokeView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	err = svc_user.RevokeTokens(user.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke tokens: %s", err)
+		response.FailWithMsg(c, "Failed to revoke sessions")
+		return
+	}
+
+	response.OKWithMsg(c, "Sessions revoked successfully")
+}
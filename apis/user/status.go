@@ -0,0 +1,52 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func (API) EnableView(c *gin.Context) {
+	setStatus(c, models.UserStatusActive)
+}
+
+func (API) DisableView(c *gin.Context) {
+	setStatus(c, models.UserStatusDisabled)
+}
+
+func setStatus(c *gin.Context, status int8) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	before := user.Status
+	if status == models.UserStatusDisabled && svc_user.IsLastAdmin(user) {
+		response.FailWithMsg(c, "Cannot disable the last admin")
+		return
+	}
+
+	err = global.DB.Model(&user).Update("status", status).Error
+	if err != nil {
+		logrus.Errorf("Failed to update user status: %s", err)
+		response.FailWithMsg(c, "Failed to update user status")
+		return
+	}
+	middlewares.AuditDiff(c, gin.H{"status": before}, gin.H{"status": status})
+	if status == models.UserStatusDisabled {
+		err = svc_user.RevokeTokens(user.ID)
+		if err != nil {
+			logrus.Errorf("Failed to revoke tokens: %s", err)
+		}
+	}
+
+	response.OKWithData(c, user)
+}
@@ -0,0 +1,33 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// UnlockView lifts the login lockout of a user
+func (API) UnlockView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+
+	err = svc_redis.UnlockLogin(user.Username)
+	if err != nil {
+		logrus.Errorf("Failed to unlock user: %s", err)
+		response.FailWithMsg(c, "Failed to unlock user")
+		return
+	}
+
+	logrus.Infof("Login of user [%s] unlocked", user.Username)
+	response.OKWithMsg(c, "User unlocked successfully")
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"fast-gin/global"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_user"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateRequest only updates the fields which are present
+type UpdateRequest struct {
+	Nickname *string `json:"nickname" binding:"omitempty,max=32" label:"nickname"`
+	RoleID   *int8   `json:"roleID" label:"roleID"`
+}
+
+func (API) UpdateView(c *gin.Context) {
+	uri := middlewares.GetBind[models.IDRequest](c)
+	req := middlewares.GetBind[UpdateRequest](c)
+
+	var user models.UserModel
+	err := global.DB.Take(&user, uri.ID).Error
+	if err != nil {
+		response.FailWithMsg(c, "User does not exist")
+		return
+	}
+	before := user
+
+	updates := make(map[string]any)
+	if req.Nickname != nil {
+		updates["nickname"] = *req.Nickname
+	}
+	if req.RoleID != nil && *req.RoleID != user.RoleID {
+		var role models.RoleModel
+		err = global.DB.Take(&role, *req.RoleID).Error
+		if err != nil {
+			response.FailWithMsg(c, "Role does not exist")
+			return
+		}
+		if svc_user.IsLastAdmin(user) {
+			response.FailWithMsg(c, "Cannot change the role of the last admin")
+			return
+		}
+		updates["role_id"] = *req.RoleID
+	}
+	if len(updates) == 0 {
+		response.OKWithData(c, user)
+		return
+	}
+
+	err = global.DB.Model(&user).Updates(updates).Error
+	if err != nil {
+		logrus.Errorf("Failed to update user: %s", err)
+		response.FailWithMsg(c, "Failed to update user")
+		return
+	}
+	middlewares.AuditDiff(c, before, user)
+	if _, ok := updates["role_id"]; ok {
+		// Tokens carry the role
+		err = svc_user.RevokeTokens(user.ID)
+		if err != nil {
+			logrus.Errorf("Failed to revoke tokens: %s", err)
+		}
+	}
+
+	response.OKWithData(c, user)
+}
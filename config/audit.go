@@ -0,0 +1,7 @@
+package config
+
+type Audit struct {
+	Retention int    `yaml:"retention" json:"retention" toml:"retention"` // Days entries are kept, 0 keeps forever
+	Buffer    int    `yaml:"buffer" json:"buffer" toml:"buffer"`          // Entries queued for the writer, more are dropped and logged
+	Cron      string `yaml:"cron" json:"cron" toml:"cron"`                // When old entries are purged, with seconds
+}
@@ -3,10 +3,12 @@ package config
 import (
 	"fmt"
 	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"strings"
 )
 
 type DBMode string
@@ -14,18 +16,34 @@ type DBMode string
 const (
 	MYSQL  DBMode = "mysql"
 	PG     DBMode = "postgres"
+	PGSQL  DBMode = "pgsql" // Alias of PG
 	SQLITE DBMode = "sqlite"
 )
 
 type DB struct {
-	Mode     DBMode `yaml:"mode"` // Supports: mysql pgsql sqlite
-	DBName   string `yaml:"db_name"`
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
+	Mode     DBMode `yaml:"mode" json:"mode" toml:"mode"` // Supports: mysql postgres (or pgsql) sqlite
+	DBName   string `yaml:"db_name" json:"db_name" toml:"db_name"`
+	Host     string `yaml:"host" json:"host" toml:"host"`
+	Port     int    `yaml:"port" json:"port" toml:"port"`
+	User     string `yaml:"user" json:"user" toml:"user"`
+	Password string `yaml:"password" json:"password" toml:"password" secret:"true"`
+
+	MaxIdleConns    int `yaml:"max_idle_conns" json:"max_idle_conns" toml:"max_idle_conns"`
+	MaxOpenConns    int `yaml:"max_open_conns" json:"max_open_conns" toml:"max_open_conns"`             // 0 is unlimited
+	ConnMaxLifetime int `yaml:"conn_max_lifetime" json:"conn_max_lifetime" toml:"conn_max_lifetime"`    // Seconds, 0 keeps connections forever
+	ConnMaxIdleTime int `yaml:"conn_max_idle_time" json:"conn_max_idle_time" toml:"conn_max_idle_time"` // Seconds, 0 keeps idle connections forever
+	DialTimeout     int `yaml:"dial_timeout" json:"dial_timeout" toml:"dial_timeout"`                   // Seconds, 0 is the driver default
+	ReadTimeout     int `yaml:"read_timeout" json:"read_timeout" toml:"read_timeout"`                   // Seconds, 0 is none. MySQL only
+	WriteTimeout    int `yaml:"write_timeout" json:"write_timeout" toml:"write_timeout"`                // Seconds, 0 is none. MySQL only
+	MaxRetries      int `yaml:"max_retries" json:"max_retries" toml:"max_retries"`                      // Connection attempts on start after the first fails
+	MinRetryBackoff int `yaml:"min_retry_backoff" json:"min_retry_backoff" toml:"min_retry_backoff"`    // Milliseconds before the first retry, doubled on each
+	MaxRetryBackoff int `yaml:"max_retry_backoff" json:"max_retry_backoff" toml:"max_retry_backoff"`    // Milliseconds
+	TLS             TLS `yaml:"tls" json:"tls" toml:"tls"`
 }
 
+// Name the MySQL driver knows the TLS configuration by
+const tlsName = "fast-gin"
+
 func (db DB) GetDSN() gorm.Dialector {
 	switch db.Mode {
 	case MYSQL:
@@ -36,15 +54,42 @@ func (db DB) GetDSN() gorm.Dialector {
 			db.Port,
 			db.DBName,
 		)
+		if db.DialTimeout > 0 {
+			dsn += fmt.Sprintf("&timeout=%ds", db.DialTimeout)
+		}
+		if db.ReadTimeout > 0 {
+			dsn += fmt.Sprintf("&readTimeout=%ds", db.ReadTimeout)
+		}
+		if db.WriteTimeout > 0 {
+			dsn += fmt.Sprintf("&writeTimeout=%ds", db.WriteTimeout)
+		}
+		tlsConfig, err := db.TLS.Config()
+		if err != nil {
+			logrus.Fatalf("Failed to load database TLS configuration: %s", err)
+		}
+		if tlsConfig != nil {
+			if tlsConfig.ServerName == "" {
+				tlsConfig.ServerName = db.Host
+			}
+			err = mysqldriver.RegisterTLSConfig(tlsName, tlsConfig)
+			if err != nil {
+				logrus.Fatalf("Failed to load database TLS configuration: %s", err)
+			}
+			dsn += "&tls=" + tlsName
+		}
 		return mysql.Open(dsn)
-	case PG:
-		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Shanghai",
+	case PG, PGSQL:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d TimeZone=Asia/Shanghai",
+			db.Host,
 			db.User,
 			db.Password,
-			db.Host,
-			db.Port,
 			db.DBName,
+			db.Port,
 		)
+		if db.DialTimeout > 0 {
+			dsn += fmt.Sprintf(" connect_timeout=%d", db.DialTimeout)
+		}
+		dsn += db.pgSSL()
 		return postgres.Open(dsn)
 	case SQLITE:
 		return sqlite.Open(db.DBName)
@@ -56,3 +101,29 @@ func (db DB) GetDSN() gorm.Dialector {
 		return nil
 	}
 }
+
+// pgSSL returns the libpq style sslmode and file parameters
+func (db DB) pgSSL() string {
+	t := db.TLS
+	if !t.Enable {
+		return " sslmode=disable"
+	}
+	params := " sslmode=verify-full"
+	if t.InsecureSkipVerify {
+		params = " sslmode=require"
+	}
+	if t.CAFile != "" {
+		params += " sslrootcert=" + pgQuote(t.CAFile)
+	}
+	if t.CertFile != "" {
+		params += " sslcert=" + pgQuote(t.CertFile) + " sslkey=" + pgQuote(t.KeyFile)
+	}
+	return params
+}
+
+// pgQuote quotes a libpq keyword value, so paths may contain spaces and quotes
+func pgQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
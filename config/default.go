@@ -0,0 +1,99 @@
+package config
+
+// Default returns the configuration every file is decoded over, so a file
+// only needs the values it changes
+func Default() *Config {
+	return &Config{
+		DB: DB{
+			Mode:   SQLITE,
+			DBName: "fast-gin.db",
+			Host:   "127.0.0.1",
+			Port:   3306,
+			User:   "root",
+
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: 3600,
+			MaxRetries:      3,
+			MinRetryBackoff: 500,
+			MaxRetryBackoff: 5000,
+		},
+		Redis: Redis{
+			Addr: "127.0.0.1:6379",
+		},
+		Gin: Gin{
+			IP:        "127.0.0.1",
+			Port:      "8080",
+			Mode:      "release",
+			RateLimit: 1,
+		},
+		JWT: JWT{
+			Algorithm:         "HS256",
+			Expire:            1,
+			RefreshExpire:     168,
+			ImpersonateExpire: 15,
+			Issuer:            "fast-gin",
+			Extractors:        []string{"bearer", "header", "cookie"},
+			QueryParam:        "access_token",
+			Cookie: JWTCookie{
+				Name:        "access_token",
+				RefreshName: "refresh_token",
+				CSRFName:    "csrf_token",
+				Path:        "/",
+				Secure:      true,
+				SameSite:    "lax",
+			},
+		},
+		Upload: Upload{
+			Size: 2,
+			Dir:  "images",
+		},
+		Site: Site{
+			URL: "http://127.0.0.1:8080",
+			Login: SiteLogin{
+				Captcha:       true,
+				CaptchaAfter:  3,
+				MaxFailures:   5,
+				IPMaxFailures: 20,
+				FailureWindow: 15,
+				Lockout:       15,
+				MaxLockout:    1440,
+			},
+		},
+		Mail: Mail{
+			Driver: OUTBOX,
+			Port:   587,
+			From:   "fast-gin <no-reply@example.com>",
+			Outbox: "./outbox",
+		},
+		OIDC: OIDC{
+			Scopes:        []string{"openid", "profile", "email"},
+			AutoProvision: true,
+			RoleID:        2,
+		},
+		Audit: Audit{
+			Retention: 180,
+			Buffer:    1024,
+			Cron:      "0 30 3 * * *",
+		},
+		Password: Password{
+			Hasher:     ARGON2ID,
+			BcryptCost: 12,
+			Argon2: Argon2{
+				Memory:      64 * 1024,
+				Iterations:  3,
+				Parallelism: 2,
+				SaltLength:  16,
+				KeyLength:   32,
+			},
+			Policy: PasswordPolicy{
+				MinLength:  8,
+				MaxLength:  72,
+				MinClasses: 2,
+			},
+		},
+		Log: Log{
+			Level: "debug",
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces secrets in output meant for humans
+const Redacted = "******"
+
+// SecretKeys returns the keys tagged secret:"true", e.g. db.password
+func SecretKeys() (keys []string) {
+	walk(reflect.TypeFor[Config](), "", func(key string, _ []int, field reflect.StructField) {
+		if field.Tag.Get("secret") == "true" {
+			keys = append(keys, key)
+		}
+	})
+	return
+}
+
+// Redact returns a copy of c with every non-empty secret replaced by Redacted
+func (c *Config) Redact() *Config {
+	redacted := *c
+	v := reflect.ValueOf(&redacted).Elem()
+	walk(reflect.TypeFor[Config](), "", func(_ string, index []int, field reflect.StructField) {
+		if field.Tag.Get("secret") != "true" {
+			return
+		}
+		if f := v.FieldByIndex(index); f.String() != "" {
+			f.SetString(Redacted)
+		}
+	})
+	return &redacted
+}
+
+// Get returns the value at the dotted YAML path key
+func (c *Config) Get(key string) (value any, ok bool) {
+	v := reflect.ValueOf(c).Elem()
+	walk(reflect.TypeFor[Config](), "", func(k string, index []int, _ reflect.StructField) {
+		if k == key {
+			value, ok = v.FieldByIndex(index).Interface(), true
+		}
+	})
+	return
+}
+
+// SetNode writes value at the dotted YAML path key of the document doc,
+// creating mappings on the way. Comments and the position of existing keys
+// are kept, so the file reads as before apart from the value.
+func SetNode(doc *yaml.Node, key string, value any) error {
+	var encoded yaml.Node
+	err := encoded.Encode(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	node := doc.Content[0]
+	for _, name := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: %s is not a mapping", key, name)
+		}
+		child := lookup(node, name)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, child)
+		}
+		node = child
+	}
+
+	node.Kind = encoded.Kind
+	node.Tag = encoded.Tag
+	node.Value = encoded.Value
+	node.Content = encoded.Content
+	if node.Kind != yaml.ScalarNode {
+		// Scalars keep their quoting, flow lists stay flow
+		node.Style = encoded.Style | node.Style&yaml.FlowStyle
+	}
+	return nil
+}
@@ -1,10 +1,15 @@
 package config
 
 type Config struct {
-	DB     DB     `yaml:"db"`
-	Redis  Redis  `yaml:"redis"`
-	Gin    Gin    `yaml:"gin"`
-	JWT    JWT    `yaml:"jwt"`
-	Upload Upload `yaml:"upload"`
-	Site   Site   `yaml:"site"`
+	DB       DB       `yaml:"db" json:"db" toml:"db"`
+	Redis    Redis    `yaml:"redis" json:"redis" toml:"redis"`
+	Gin      Gin      `yaml:"gin" json:"gin" toml:"gin"`
+	JWT      JWT      `yaml:"jwt" json:"jwt" toml:"jwt"`
+	Upload   Upload   `yaml:"upload" json:"upload" toml:"upload"`
+	Site     Site     `yaml:"site" json:"site" toml:"site"`
+	Mail     Mail     `yaml:"mail" json:"mail" toml:"mail"`
+	OIDC     OIDC     `yaml:"oidc" json:"oidc" toml:"oidc"`
+	Audit    Audit    `yaml:"audit" json:"audit" toml:"audit"`
+	Password Password `yaml:"password" json:"password" toml:"password"`
+	Log      Log      `yaml:"log" json:"log" toml:"log"`
 }
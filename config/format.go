@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
+)
+
+// Format of a settings file, chosen by its extension
+type Format string
+
+const (
+	YAML Format = "yaml" // .yaml .yml
+	TOML Format = "toml" // .toml
+	JSON Format = "json" // .json
+)
+
+// FormatOf returns the format of file by its extension
+func FormatOf(file string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".toml":
+		return TOML, nil
+	case ".json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("%s: unsupported configuration format, expected .yaml .yml .toml or .json", file)
+}
+
+// Parse reads byteData of format as a YAML document, which the layering,
+// interpolation and dumping work on whatever the format. Only YAML keeps
+// comments and key order.
+func Parse(format Format, byteData []byte) (*yaml.Node, error) {
+	node := new(yaml.Node)
+	var data map[string]any
+	var err error
+	switch format {
+	case YAML:
+		err = yaml.Unmarshal(byteData, node)
+		if err == nil && node.Kind == 0 {
+			// Empty file
+			node = NewDocument()
+		}
+		return node, err
+	case TOML:
+		err = toml.Unmarshal(byteData, &data)
+	case JSON:
+		if len(bytes.TrimSpace(byteData)) == 0 {
+			return NewDocument(), nil
+		}
+		err = json.Unmarshal(byteData, &data)
+	default:
+		return nil, fmt.Errorf("unsupported configuration format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	document := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{}}}
+	err = document.Content[0].Encode(data)
+	return document, err
+}
+
+// Encode writes the document node in format, YAML indented like settings.yaml
+func Encode(format Format, node *yaml.Node) ([]byte, error) {
+	if format == YAML {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		err := encoder.Encode(node)
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Close()
+		return buf.Bytes(), err
+	}
+
+	var data map[string]any
+	err := node.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case TOML:
+		return toml.Marshal(data)
+	case JSON:
+		byteData, err := json.MarshalIndent(data, "", "  ")
+		return append(byteData, '\n'), err
+	}
+	return nil, fmt.Errorf("unsupported configuration format: %s", format)
+}
+
+// NewDocument returns an empty YAML document
+func NewDocument() *yaml.Node {
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+}
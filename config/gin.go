@@ -3,9 +3,10 @@ package config
 import "fmt"
 
 type Gin struct {
-	IP   string `yaml:"ip"`
-	Port string `yaml:"port"`
-	Mode string `yaml:"mode"`
+	IP        string `yaml:"ip" json:"ip" toml:"ip"`
+	Port      string `yaml:"port" json:"port" toml:"port"`
+	Mode      string `yaml:"mode" json:"mode" toml:"mode"`
+	RateLimit int    `yaml:"rate_limit" json:"rate_limit" toml:"rate_limit"` // Requests per second per IP on rate limited routes
 }
 
 func (g Gin) Addr() string {
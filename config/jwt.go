@@ -1,7 +1,33 @@
 package config
 
+type JWTKey struct {
+	KID        string `yaml:"kid" json:"kid" toml:"kid"`
+	PrivateKey string `yaml:"private_key" json:"private_key" toml:"private_key"` // PEM file, only required for the signing key
+	PublicKey  string `yaml:"public_key" json:"public_key" toml:"public_key"`    // PEM file, derived from private key if empty
+}
+
+type JWTCookie struct {
+	Enable      bool   `yaml:"enable" json:"enable" toml:"enable"`                   // Also hand out tokens as HttpOnly cookies on login
+	Name        string `yaml:"name" json:"name" toml:"name"`                         // Access token cookie
+	RefreshName string `yaml:"refresh_name" json:"refresh_name" toml:"refresh_name"` // Refresh token cookie
+	CSRFName    string `yaml:"csrf_name" json:"csrf_name" toml:"csrf_name"`          // Readable cookie, echoed back in the X-CSRF-Token header
+	Domain      string `yaml:"domain" json:"domain" toml:"domain"`
+	Path        string `yaml:"path" json:"path" toml:"path"`
+	Secure      bool   `yaml:"secure" json:"secure" toml:"secure"`
+	SameSite    string `yaml:"same_site" json:"same_site" toml:"same_site"` // Supports: lax strict none
+}
+
 type JWT struct {
-	Expire    int    `yaml:"expire"`
-	Issuer    string `yaml:"issuer"`
-	SecretKey string `yaml:"secret_key"`
+	Algorithm         string   `yaml:"algorithm" json:"algorithm" toml:"algorithm"`                            // Supports: HS256 RS256 ES256 EdDSA
+	Expire            int      `yaml:"expire" json:"expire" toml:"expire"`                                     // Access token lifetime in hours
+	RefreshExpire     int      `yaml:"refresh_expire" json:"refresh_expire" toml:"refresh_expire"`             // Refresh token lifetime in hours
+	ImpersonateExpire int      `yaml:"impersonate_expire" json:"impersonate_expire" toml:"impersonate_expire"` // Impersonation token lifetime in minutes
+	Issuer            string   `yaml:"issuer" json:"issuer" toml:"issuer"`
+	SecretKey         string   `yaml:"secret_key" json:"secret_key" toml:"secret_key" secret:"true"` // HS256 only
+	SigningKID        string   `yaml:"signing_kid" json:"signing_kid" toml:"signing_kid"`            // Asymmetric only, kid of the key used to sign
+	Keys              []JWTKey `yaml:"keys" json:"keys" toml:"keys"`                                 // Asymmetric only, every key accepted for verification
+
+	Extractors []string  `yaml:"extractors" json:"extractors" toml:"extractors"`    // Where the access token is read from, in order. Supports: bearer header query cookie
+	QueryParam string    `yaml:"query_param" json:"query_param" toml:"query_param"` // Used by the query extractor
+	Cookie     JWTCookie `yaml:"cookie" json:"cookie" toml:"cookie"`
 }
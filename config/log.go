@@ -0,0 +1,5 @@
+package config
+
+type Log struct {
+	Level string `yaml:"level" json:"level" toml:"level"` // Supports: trace debug info warn error
+}
@@ -0,0 +1,18 @@
+package config
+
+type MailDriver string
+
+const (
+	SMTP   MailDriver = "smtp"
+	OUTBOX MailDriver = "outbox"
+)
+
+type Mail struct {
+	Driver   MailDriver `yaml:"driver" json:"driver" toml:"driver"` // Supports: smtp outbox
+	Host     string     `yaml:"host" json:"host" toml:"host"`
+	Port     int        `yaml:"port" json:"port" toml:"port"`
+	Username string     `yaml:"username" json:"username" toml:"username"`
+	Password string     `yaml:"password" json:"password" toml:"password" secret:"true"`
+	From     string     `yaml:"from" json:"from" toml:"from"`
+	Outbox   string     `yaml:"outbox" json:"outbox" toml:"outbox"` // Directory mails are written to by outbox driver
+}
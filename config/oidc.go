@@ -0,0 +1,14 @@
+package config
+
+type OIDC struct {
+	Enable        bool     `yaml:"enable" json:"enable" toml:"enable"`
+	Issuer        string   `yaml:"issuer" json:"issuer" toml:"issuer"` // Discovery is read from <issuer>/.well-known/openid-configuration
+	ClientID      string   `yaml:"client_id" json:"client_id" toml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret" json:"client_secret" toml:"client_secret" secret:"true"`
+	RedirectURL   string   `yaml:"redirect_url" json:"redirect_url" toml:"redirect_url"` // Must point at /v1/users/oidc/callback
+	Scopes        []string `yaml:"scopes" json:"scopes" toml:"scopes"`
+	AutoProvision bool     `yaml:"auto_provision" json:"auto_provision" toml:"auto_provision"` // Create a user on first login
+	LinkByEmail   bool     `yaml:"link_by_email" json:"link_by_email" toml:"link_by_email"`    // Link an existing user with the same verified email
+	RoleID        int8     `yaml:"role_id" json:"role_id" toml:"role_id"`                      // Role of provisioned users
+	PasswordLogin bool     `yaml:"password_login" json:"password_login" toml:"password_login"` // Allow linked users to log in with a local password too
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix starts the environment variables overriding configuration,
+// db.password is overridden by FASTGIN_DB_PASSWORD
+const EnvPrefix = "FASTGIN_"
+
+// ${NAME} or ${NAME:-default}
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Interpolate replaces ${NAME} and ${NAME:-default} in every scalar of node
+// with the environment variable NAME, returning the names which were unset
+// and had no default
+func Interpolate(node *yaml.Node) (missing []string) {
+	if node.Kind == yaml.ScalarNode {
+		node.Value = envRef.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			m := envRef.FindStringSubmatch(ref)
+			if value, ok := os.LookupEnv(m[1]); ok {
+				return value
+			}
+			if m[2] == "" {
+				missing = append(missing, m[1])
+			}
+			return m[3]
+		})
+		return
+	}
+	for _, child := range node.Content {
+		missing = append(missing, Interpolate(child)...)
+	}
+	return
+}
+
+// Keys returns the dotted YAML path of every leaf of Config, e.g. db.password
+func Keys() []string {
+	var keys []string
+	walk(reflect.TypeFor[Config](), "", func(key string, _ []int, _ reflect.StructField) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvName returns the environment variable overriding key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Set overrides the value at the dotted YAML path key, value is parsed as
+// YAML into the type of the field, so lists are written as [a, b]
+func (c *Config) Set(key, value string) error {
+	var index []int
+	walk(reflect.TypeFor[Config](), "", func(k string, i []int, _ reflect.StructField) {
+		if k == key {
+			index = i
+		}
+	})
+	if index == nil {
+		return fmt.Errorf("unknown configuration key: %s", key)
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(index)
+	if field.Kind() == reflect.String {
+		// Taken as is, YAML would turn "" into null and strip quotes
+		field.SetString(value)
+		return nil
+	}
+	ptr := reflect.New(field.Type())
+	err := yaml.Unmarshal([]byte(value), ptr.Interface())
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	field.Set(ptr.Elem())
+	return nil
+}
+
+// ApplyEnv overrides every key whose environment variable is set, returning
+// the keys overridden
+func (c *Config) ApplyEnv() (applied []string, err error) {
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err = c.Set(key, value); err != nil {
+			return applied, fmt.Errorf("%s: %w", EnvName(key), err)
+		}
+		applied = append(applied, key)
+	}
+	return
+}
+
+// walk calls fn for every leaf field below t with its dotted YAML path, index
+// and field. Structs are descended into, anything else is a leaf.
+func walk(t reflect.Type, prefix string, fn func(key string, index []int, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if f.Type.Kind() == reflect.Struct {
+			walk(f.Type, key, func(k string, index []int, field reflect.StructField) {
+				fn(k, append([]int{i}, index...), field)
+			})
+			continue
+		}
+		fn(key, []int{i}, f)
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+type PasswordHasher string
+
+const (
+	BCRYPT   PasswordHasher = "bcrypt"
+	ARGON2ID PasswordHasher = "argon2id"
+)
+
+type Argon2 struct {
+	Memory      uint32 `yaml:"memory" json:"memory" toml:"memory"` // KiB
+	Iterations  uint32 `yaml:"iterations" json:"iterations" toml:"iterations"`
+	Parallelism uint8  `yaml:"parallelism" json:"parallelism" toml:"parallelism"`
+	SaltLength  uint32 `yaml:"salt_length" json:"salt_length" toml:"salt_length"` // Bytes
+	KeyLength   uint32 `yaml:"key_length" json:"key_length" toml:"key_length"`    // Bytes
+}
+
+type PasswordPolicy struct {
+	MinLength  int    `yaml:"min_length" json:"min_length" toml:"min_length"`    // Characters
+	MaxLength  int    `yaml:"max_length" json:"max_length" toml:"max_length"`    // Bytes of UTF-8, 0 for no maximum, capped at 72 with bcrypt which ignores the rest
+	MinClasses int    `yaml:"min_classes" json:"min_classes" toml:"min_classes"` // Of lower, upper, digit and symbol
+	Breached   string `yaml:"breached" json:"breached" toml:"breached"`          // File of known breached passwords, one per line, plain or SHA-1 hex
+}
+
+type Password struct {
+	Hasher     PasswordHasher `yaml:"hasher" json:"hasher" toml:"hasher"`                // Supports: bcrypt argon2id
+	BcryptCost int            `yaml:"bcrypt_cost" json:"bcrypt_cost" toml:"bcrypt_cost"` // 4-31
+	Argon2     Argon2         `yaml:"argon2" json:"argon2" toml:"argon2"`
+	Policy     PasswordPolicy `yaml:"policy" json:"policy" toml:"policy"`
+}
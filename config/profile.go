@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProfileEnv selects the profile when -profile is not given
+const ProfileEnv = EnvPrefix + "PROFILE"
+
+// Source of a value which did not come from any file
+const (
+	SourceDefault = "default"
+	SourceEnv     = "environment"
+	SourceFlag    = "-set"
+)
+
+// Sources maps the dotted YAML path of every value to where it came from,
+// a file name or one of the Source constants
+type Sources map[string]string
+
+// ProfileFile returns the overlay of base for profile,
+// ./config/settings.yaml with profile dev is ./config/settings-dev.yaml
+func ProfileFile(base, profile string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + profile + ext
+}
+
+// ReadLayer reads file, YAML, TOML or JSON by its extension, as a YAML
+// document with ${ENV} interpolated, returning the names of unset
+// environment variables it references
+func ReadLayer(file string) (node *yaml.Node, missing []string, err error) {
+	format, err := FormatOf(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	byteData, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err = Parse(format, byteData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if node.Kind != yaml.DocumentNode || node.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%s: top level must be a mapping", file)
+	}
+	return node, Interpolate(node), nil
+}
+
+// Merge deep-merges the document overlay into base. Mappings are merged key
+// by key, anything else in overlay replaces the value in base. Every path
+// overlay supplies is recorded in sources under file.
+func Merge(base, overlay *yaml.Node, file string, sources Sources) {
+	mergeMapping(base.Content[0], overlay.Content[0], "", file, sources)
+}
+
+func mergeMapping(dst, src *yaml.Node, prefix, file string, sources Sources) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		existing := lookup(dst, key.Value)
+		if existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeMapping(existing, value, path, file, sources)
+			continue
+		}
+		record(value, path, file, sources)
+		if existing != nil {
+			*existing = *value
+			continue
+		}
+		dst.Content = append(dst.Content, key, value)
+	}
+}
+
+// record attributes every leaf below value to file
+func record(value *yaml.Node, path, file string, sources Sources) {
+	if value.Kind != yaml.MappingNode {
+		sources[path] = file
+		return
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		record(value.Content[i+1], path+"."+value.Content[i].Value, file, sources)
+	}
+}
+
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -1,7 +1,62 @@
 package config
 
+import (
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
 type Redis struct {
-	Addr     string `yaml:"addr"`
-	Password string `yaml:"password"`
-	DB       int    `yaml:"db"`
+	Addr     string `yaml:"addr" json:"addr" toml:"addr"`
+	Password string `yaml:"password" json:"password" toml:"password" secret:"true"`
+	DB       int    `yaml:"db" json:"db" toml:"db"`
+
+	PoolSize        int `yaml:"pool_size" json:"pool_size" toml:"pool_size"` // 0 is 10 per CPU
+	MinIdleConns    int `yaml:"min_idle_conns" json:"min_idle_conns" toml:"min_idle_conns"`
+	MaxIdleConns    int `yaml:"max_idle_conns" json:"max_idle_conns" toml:"max_idle_conns"`             // 0 keeps all
+	MaxActiveConns  int `yaml:"max_active_conns" json:"max_active_conns" toml:"max_active_conns"`       // 0 is unlimited
+	ConnMaxIdleTime int `yaml:"conn_max_idle_time" json:"conn_max_idle_time" toml:"conn_max_idle_time"` // Seconds, 0 is 30 minutes, -1 keeps idle connections forever
+	ConnMaxLifetime int `yaml:"conn_max_lifetime" json:"conn_max_lifetime" toml:"conn_max_lifetime"`    // Seconds, 0 keeps connections forever
+	PoolTimeout     int `yaml:"pool_timeout" json:"pool_timeout" toml:"pool_timeout"`                   // Seconds waiting for a free connection, 0 is read_timeout + 1
+	DialTimeout     int `yaml:"dial_timeout" json:"dial_timeout" toml:"dial_timeout"`                   // Seconds, 0 is 5
+	ReadTimeout     int `yaml:"read_timeout" json:"read_timeout" toml:"read_timeout"`                   // Seconds, 0 is 3, -1 blocks
+	WriteTimeout    int `yaml:"write_timeout" json:"write_timeout" toml:"write_timeout"`                // Seconds, 0 is read_timeout, -1 blocks
+	MaxRetries      int `yaml:"max_retries" json:"max_retries" toml:"max_retries"`                      // Per command, 0 is 3, -1 disables
+	MinRetryBackoff int `yaml:"min_retry_backoff" json:"min_retry_backoff" toml:"min_retry_backoff"`    // Milliseconds, 0 is 8, -1 disables
+	MaxRetryBackoff int `yaml:"max_retry_backoff" json:"max_retry_backoff" toml:"max_retry_backoff"`    // Milliseconds, 0 is 512, -1 disables
+	TLS             TLS `yaml:"tls" json:"tls" toml:"tls"`
+}
+
+// Options builds the client options, zero leaves the go-redis default
+func (r Redis) Options() (*redis.Options, error) {
+	tlsConfig, err := r.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+	return &redis.Options{
+		Addr:            r.Addr,
+		Password:        r.Password,
+		DB:              r.DB,
+		PoolSize:        r.PoolSize,
+		MinIdleConns:    r.MinIdleConns,
+		MaxIdleConns:    r.MaxIdleConns,
+		MaxActiveConns:  r.MaxActiveConns,
+		ConnMaxIdleTime: duration(r.ConnMaxIdleTime, time.Second),
+		ConnMaxLifetime: duration(r.ConnMaxLifetime, time.Second),
+		PoolTimeout:     duration(r.PoolTimeout, time.Second),
+		DialTimeout:     duration(r.DialTimeout, time.Second),
+		ReadTimeout:     duration(r.ReadTimeout, time.Second),
+		WriteTimeout:    duration(r.WriteTimeout, time.Second),
+		MaxRetries:      r.MaxRetries,
+		MinRetryBackoff: duration(r.MinRetryBackoff, time.Millisecond),
+		MaxRetryBackoff: duration(r.MaxRetryBackoff, time.Millisecond),
+		TLSConfig:       tlsConfig,
+	}, nil
+}
+
+// duration converts n units, keeping -1 which go-redis reads as disabled
+func duration(n int, unit time.Duration) time.Duration {
+	if n < 0 {
+		return -1
+	}
+	return time.Duration(n) * unit
 }
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Immutable maps keys, or key prefixes ending in ".", which cannot change
+// while running to the reason why
+var Immutable = map[string]string{
+	"db.":          "the database connection is opened on start",
+	"redis.":       "the Redis connection is opened on start",
+	"gin.ip":       "the listen address is bound on start",
+	"gin.port":     "the listen address is bound on start",
+	"gin.mode":     "gin mode is applied when routes are registered",
+	"audit.buffer": "the audit writer is started on start",
+	"audit.cron":   "cron jobs are scheduled on start",
+}
+
+// Changed returns the keys whose values differ between a and b
+func Changed(a, b *Config) (keys []string) {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	walk(reflect.TypeFor[Config](), "", func(key string, index []int, _ reflect.StructField) {
+		if !reflect.DeepEqual(va.FieldByIndex(index).Interface(), vb.FieldByIndex(index).Interface()) {
+			keys = append(keys, key)
+		}
+	})
+	return
+}
+
+// ImmutableReason returns why key cannot change while running, or "" if it can
+func ImmutableReason(key string) string {
+	for prefix, reason := range Immutable {
+		if key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix)) {
+			return reason
+		}
+	}
+	return ""
+}
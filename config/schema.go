@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the JSON Schema draft Schema follows
+const SchemaID = "https://json-schema.org/draft/2020-12/schema"
+
+// Choices lists the values of keys accepting a fixed set, checked by Validate
+// and offered by Schema. For lists they apply to every item.
+var Choices = map[string][]string{
+	"db.mode":              {string(MYSQL), string(PG), string(PGSQL), string(SQLITE)},
+	"gin.mode":             {"debug", "release", "test"},
+	"jwt.algorithm":        {"HS256", "RS256", "ES256", "EdDSA"},
+	"jwt.extractors":       {"bearer", "header", "query", "cookie"},
+	"jwt.cookie.same_site": {"lax", "strict", "none"},
+	"mail.driver":          {string(SMTP), string(OUTBOX)},
+	"password.hasher":      {string(BCRYPT), string(ARGON2ID)},
+	"log.level":            {"trace", "debug", "info", "warn", "error"},
+}
+
+// NumericStrings are string keys settings files may write as numbers
+var NumericStrings = map[string]bool{
+	"gin.port": true,
+}
+
+// Schema returns a JSON Schema of Config, so editors can complete and check
+// settings files. Unknown keys are rejected and defaults come from Default.
+func Schema() map[string]any {
+	schema := schemaOf(reflect.TypeFor[Config](), "", reflect.ValueOf(Default()).Elem(), "")
+	schema["$schema"] = SchemaID
+	schema["$defs"] = map[string]any{
+		"env_ref": map[string]any{
+			"type":    "string",
+			"pattern": envRef.String(),
+		},
+	}
+	schema["title"] = "fast-gin configuration"
+	return schema
+}
+
+func schemaOf(t reflect.Type, key string, def reflect.Value, secret string) map[string]any {
+	schema := map[string]any{}
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			child := name
+			if key != "" {
+				child = key + "." + name
+			}
+			var fieldDef reflect.Value
+			if def.IsValid() {
+				fieldDef = def.Field(i)
+			}
+			properties[name] = schemaOf(f.Type, child, fieldDef, f.Tag.Get("secret"))
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+		return schema
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = schemaOf(t.Elem(), key, reflect.Value{}, "")
+	case reflect.String:
+		schema["type"] = "string"
+		if NumericStrings[key] {
+			schema["type"] = []string{"string", "integer"}
+		}
+		if choices, ok := Choices[key]; ok {
+			schema["enum"] = choices
+			schema = interpolatable(schema)
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+		schema = interpolatable(schema)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema["type"] = "integer"
+		schema = interpolatable(schema)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+		schema["minimum"] = 0
+		schema = interpolatable(schema)
+	}
+
+	if secret == "true" {
+		schema["description"] = "Secret, may be a file:/path, env:NAME or enc:<ciphertext> reference"
+		return schema
+	}
+	if def.IsValid() && !def.IsZero() {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
+
+// interpolatable also accepts a ${NAME} reference in place of a value of
+// schema, references are replaced by Interpolate before decoding
+func interpolatable(schema map[string]any) map[string]any {
+	return map[string]any{
+		"anyOf": []any{schema, map[string]any{"$ref": "#/$defs/env_ref"}},
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fast-gin/utils/aes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// The master key enc: secrets are encrypted with, base64 of 32 bytes, is read
+// from MasterKeyEnv or else the file named by MasterKeyFileEnv
+const (
+	MasterKeyEnv     = EnvPrefix + "MASTER_KEY"
+	MasterKeyFileEnv = EnvPrefix + "MASTER_KEY_FILE"
+)
+
+// Prefixes of secret references, fields tagged secret:"true" may hold one
+// instead of the secret itself
+const (
+	SecretFile = "file:" // file:/run/secrets/db_password, trailing newlines are trimmed
+	SecretEnv  = "env:"  // env:DB_PASSWORD
+	SecretEnc  = "enc:"  // enc:<ciphertext>, made by -res config -op encrypt
+)
+
+var ErrNoMasterKey = errors.New("no master key, set " + MasterKeyEnv + " or " + MasterKeyFileEnv)
+
+// MasterKey returns the key enc: secrets are decrypted with
+func MasterKey() ([]byte, error) {
+	encoded, ok := os.LookupEnv(MasterKeyEnv)
+	if !ok {
+		file, ok := os.LookupEnv(MasterKeyFileEnv)
+		if !ok {
+			return nil, ErrNoMasterKey
+		}
+		byteData, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(byteData)
+	}
+	return aes.ParseKey(strings.TrimSpace(encoded))
+}
+
+// EncryptSecret returns the enc: reference of plain
+func EncryptSecret(key []byte, plain string) (string, error) {
+	text, err := aes.Encrypt(key, plain)
+	if err != nil {
+		return "", err
+	}
+	return SecretEnc + text, nil
+}
+
+// ResolveSecret returns the value ref points to, anything without a secret
+// prefix is returned as is. The master key is only read for enc: references.
+func ResolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, SecretFile):
+		byteData, err := os.ReadFile(strings.TrimPrefix(ref, SecretFile))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(byteData), "\r\n"), nil
+	case strings.HasPrefix(ref, SecretEnv):
+		name := strings.TrimPrefix(ref, SecretEnv)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, SecretEnc):
+		key, err := MasterKey()
+		if err != nil {
+			return "", err
+		}
+		return aes.Decrypt(key, strings.TrimPrefix(ref, SecretEnc))
+	}
+	return ref, nil
+}
+
+// ResolveSecrets replaces every secret field holding a reference with the
+// value it points to, returning the keys resolved
+func (c *Config) ResolveSecrets() (resolved []string, err error) {
+	var problems Problems
+	v := reflect.ValueOf(c).Elem()
+	walk(reflect.TypeFor[Config](), "", func(key string, index []int, field reflect.StructField) {
+		if field.Tag.Get("secret") != "true" {
+			return
+		}
+		f := v.FieldByIndex(index)
+		value, err := ResolveSecret(f.String())
+		if err != nil {
+			problems.add(key, "%s", err)
+			return
+		}
+		if value != f.String() {
+			f.SetString(value)
+			resolved = append(resolved, key)
+		}
+	})
+	if len(problems) > 0 {
+		return resolved, problems
+	}
+	return resolved, nil
+}
+
+// Rekey re-encrypts the enc: value of every secret key in the settings
+// document doc from oldKey to newKey, returning how many there were. Only
+// the values of fields tagged secret:"true" are touched, comments and
+// other values stay as they are.
+func Rekey(doc *yaml.Node, oldKey, newKey []byte) (count int, err error) {
+	for _, key := range SecretKeys() {
+		node := doc.Content[0]
+		for _, name := range strings.Split(key, ".") {
+			if node == nil || node.Kind != yaml.MappingNode {
+				node = nil
+				break
+			}
+			node = lookup(node, name)
+		}
+		if node == nil || node.Kind != yaml.ScalarNode || !strings.HasPrefix(node.Value, SecretEnc) {
+			continue
+		}
+
+		plain, err := aes.Decrypt(oldKey, strings.TrimPrefix(node.Value, SecretEnc))
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", key, err)
+		}
+		node.Value, err = EncryptSecret(newKey, plain)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", key, err)
+		}
+		count++
+	}
+	return count, nil
+}
@@ -1,9 +1,17 @@
 package config
 
 type SiteLogin struct {
-	Captcha bool `yaml:"captcha"`
+	Captcha       bool `yaml:"captcha" json:"captcha" toml:"captcha"`                         // Require captcha on login
+	CaptchaAfter  int  `yaml:"captcha_after" json:"captcha_after" toml:"captcha_after"`       // Require captcha only after this many failures, 0 always
+	MaxFailures   int  `yaml:"max_failures" json:"max_failures" toml:"max_failures"`          // Failures per username before lockout, 0 disables lockout
+	IPMaxFailures int  `yaml:"ip_max_failures" json:"ip_max_failures" toml:"ip_max_failures"` // Failures per IP before lockout, 0 disables lockout
+	FailureWindow int  `yaml:"failure_window" json:"failure_window" toml:"failure_window"`    // Minutes failures are counted within
+	Lockout       int  `yaml:"lockout" json:"lockout" toml:"lockout"`                         // Minutes, doubled on each further lockout
+	MaxLockout    int  `yaml:"max_lockout" json:"max_lockout" toml:"max_lockout"`             // Minutes
 }
 
 type Site struct {
-	Login SiteLogin `yaml:"login"`
+	URL      string    `yaml:"url" json:"url" toml:"url"` // Public base URL, used in links sent by email
+	Login    SiteLogin `yaml:"login" json:"login" toml:"login"`
+	Register bool      `yaml:"register" json:"register" toml:"register"` // Allow self-registration
 }
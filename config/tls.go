@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLS of the connection to a backend
+type TLS struct {
+	Enable             bool   `yaml:"enable" json:"enable" toml:"enable"`
+	CAFile             string `yaml:"ca_file" json:"ca_file" toml:"ca_file"`                                        // PEM, system roots if empty
+	CertFile           string `yaml:"cert_file" json:"cert_file" toml:"cert_file"`                                  // PEM client certificate, for mutual TLS
+	KeyFile            string `yaml:"key_file" json:"key_file" toml:"key_file"`                                     // PEM client key
+	ServerName         string `yaml:"server_name" json:"server_name" toml:"server_name"`                            // Defaults to the host connected to
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify" toml:"insecure_skip_verify"` // Testing only
+}
+
+// Config builds the tls.Config, nil if TLS is not enabled
+func (t TLS) Config() (*tls.Config, error) {
+	if !t.Enable {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificate found", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -1,6 +1,6 @@
 package config
 
 type Upload struct {
-	Size int64  `json:"size"`
-	Dir  string `json:"dir"`
+	Size int64  `yaml:"size" json:"size" toml:"size"` // MB
+	Dir  string `yaml:"dir" json:"dir" toml:"dir"`
 }
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultSecretKey is the HS256 secret shipped in settings.yaml
+const DefaultSecretKey = "my-secret-key"
+
+// Problem is an invalid or insecure value at a dotted YAML path
+type Problem struct {
+	Path string
+	Msg  string
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Msg
+}
+
+// Problems is returned by Validate with every problem found
+type Problems []Problem
+
+func (p Problems) Error() string {
+	lines := make([]string, len(p))
+	for i, problem := range p {
+		lines[i] = problem.String()
+	}
+	return "invalid configuration:\n  " + strings.Join(lines, "\n  ")
+}
+
+func (p *Problems) add(path, format string, args ...any) {
+	*p = append(*p, Problem{Path: path, Msg: fmt.Sprintf(format, args...)})
+}
+
+// oneOf reports value at path unless it is one of allowed
+func (p *Problems) oneOf(path, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		p.add(path, "%q is not supported, expected one of: %s", value, strings.Join(allowed, " "))
+	}
+}
+
+func (p *Problems) positive(path string, value int64) {
+	if value <= 0 {
+		p.add(path, "must be greater than 0, got %d", value)
+	}
+}
+
+func (p *Problems) nonNegative(path string, value int) {
+	if value < 0 {
+		p.add(path, "must not be negative, got %d", value)
+	}
+}
+
+// disableable reports values below -1, which disables the setting
+func (p *Problems) disableable(path string, value int) {
+	if value < -1 {
+		p.add(path, "must be -1 or more, got %d", value)
+	}
+}
+
+func (p *Problems) required(path, value string) {
+	if value == "" {
+		p.add(path, "is required")
+	}
+}
+
+func (p *Problems) port(path string, value int) {
+	if value < 1 || value > 65535 {
+		p.add(path, "must be a port within 1-65535, got %d", value)
+	}
+}
+
+func (p *Problems) url(path, value string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		p.add(path, "must be an absolute URL, got %q", value)
+	}
+}
+
+func (p *Problems) tls(path string, t TLS) {
+	if !t.Enable {
+		return
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		p.add(path, "cert_file and key_file must be set together")
+	}
+	for _, file := range []struct{ name, path string }{
+		{"ca_file", t.CAFile},
+		{"cert_file", t.CertFile},
+		{"key_file", t.KeyFile},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			p.add(path+"."+file.name, "%s", err)
+		}
+	}
+}
+
+// Validate checks every value, returning Problems listing all of them so
+// they can be fixed in one go
+func (c *Config) Validate() error {
+	var p Problems
+
+	// DB
+	p.oneOf("db.mode", string(c.DB.Mode), Choices["db.mode"]...)
+	p.required("db.db_name", c.DB.DBName)
+	if c.DB.Mode != SQLITE {
+		p.required("db.host", c.DB.Host)
+		p.port("db.port", c.DB.Port)
+		p.required("db.user", c.DB.User)
+	}
+	p.nonNegative("db.max_idle_conns", c.DB.MaxIdleConns)
+	p.nonNegative("db.max_open_conns", c.DB.MaxOpenConns)
+	p.nonNegative("db.conn_max_lifetime", c.DB.ConnMaxLifetime)
+	p.nonNegative("db.conn_max_idle_time", c.DB.ConnMaxIdleTime)
+	p.nonNegative("db.dial_timeout", c.DB.DialTimeout)
+	p.nonNegative("db.read_timeout", c.DB.ReadTimeout)
+	p.nonNegative("db.write_timeout", c.DB.WriteTimeout)
+	p.nonNegative("db.max_retries", c.DB.MaxRetries)
+	p.nonNegative("db.min_retry_backoff", c.DB.MinRetryBackoff)
+	p.nonNegative("db.max_retry_backoff", c.DB.MaxRetryBackoff)
+	if c.DB.MaxOpenConns > 0 && c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		p.add("db.max_idle_conns", "must not exceed max_open_conns (%d), got %d", c.DB.MaxOpenConns, c.DB.MaxIdleConns)
+	}
+	if c.DB.MaxRetryBackoff < c.DB.MinRetryBackoff {
+		p.add("db.max_retry_backoff", "must not be less than min_retry_backoff (%d), got %d", c.DB.MinRetryBackoff, c.DB.MaxRetryBackoff)
+	}
+	if c.DB.Mode != SQLITE {
+		p.tls("db.tls", c.DB.TLS)
+	}
+
+	// Redis
+	if _, _, err := net.SplitHostPort(c.Redis.Addr); err != nil {
+		p.add("redis.addr", "must be host:port, got %q", c.Redis.Addr)
+	}
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		p.add("redis.db", "must be within 0-15, got %d", c.Redis.DB)
+	}
+	p.nonNegative("redis.pool_size", c.Redis.PoolSize)
+	p.nonNegative("redis.min_idle_conns", c.Redis.MinIdleConns)
+	p.nonNegative("redis.max_idle_conns", c.Redis.MaxIdleConns)
+	p.nonNegative("redis.max_active_conns", c.Redis.MaxActiveConns)
+	p.nonNegative("redis.conn_max_lifetime", c.Redis.ConnMaxLifetime)
+	p.nonNegative("redis.pool_timeout", c.Redis.PoolTimeout)
+	p.nonNegative("redis.dial_timeout", c.Redis.DialTimeout)
+	p.disableable("redis.conn_max_idle_time", c.Redis.ConnMaxIdleTime)
+	p.disableable("redis.read_timeout", c.Redis.ReadTimeout)
+	p.disableable("redis.write_timeout", c.Redis.WriteTimeout)
+	p.disableable("redis.max_retries", c.Redis.MaxRetries)
+	p.disableable("redis.min_retry_backoff", c.Redis.MinRetryBackoff)
+	p.disableable("redis.max_retry_backoff", c.Redis.MaxRetryBackoff)
+	if c.Redis.MaxIdleConns > 0 && c.Redis.MinIdleConns > c.Redis.MaxIdleConns {
+		p.add("redis.min_idle_conns", "must not exceed max_idle_conns (%d), got %d", c.Redis.MaxIdleConns, c.Redis.MinIdleConns)
+	}
+	p.tls("redis.tls", c.Redis.TLS)
+
+	// Gin
+	if port, err := strconv.Atoi(c.Gin.Port); err != nil {
+		p.add("gin.port", "must be a port within 1-65535, got %q", c.Gin.Port)
+	} else {
+		p.port("gin.port", port)
+	}
+	if c.Gin.IP != "" && net.ParseIP(c.Gin.IP) == nil {
+		p.add("gin.ip", "must be an IP address, got %q", c.Gin.IP)
+	}
+	p.oneOf("gin.mode", c.Gin.Mode, Choices["gin.mode"]...)
+	p.positive("gin.rate_limit", int64(c.Gin.RateLimit))
+
+	// JWT
+	p.oneOf("jwt.algorithm", c.JWT.Algorithm, Choices["jwt.algorithm"]...)
+	if c.JWT.Algorithm == "HS256" {
+		p.required("jwt.secret_key", c.JWT.SecretKey)
+	} else {
+		p.required("jwt.signing_kid", c.JWT.SigningKID)
+		if len(c.JWT.Keys) == 0 {
+			p.add("jwt.keys", "is required for %s", c.JWT.Algorithm)
+		}
+		for i, key := range c.JWT.Keys {
+			p.required(fmt.Sprintf("jwt.keys[%d].kid", i), key.KID)
+			if key.PrivateKey == "" && key.PublicKey == "" {
+				p.add(fmt.Sprintf("jwt.keys[%d]", i), "needs a private_key or public_key")
+			}
+		}
+	}
+	p.positive("jwt.expire", int64(c.JWT.Expire))
+	p.positive("jwt.refresh_expire", int64(c.JWT.RefreshExpire))
+	p.positive("jwt.impersonate_expire", int64(c.JWT.ImpersonateExpire))
+	for i, name := range c.JWT.Extractors {
+		p.oneOf(fmt.Sprintf("jwt.extractors[%d]", i), name, Choices["jwt.extractors"]...)
+	}
+	if slices.Contains(c.JWT.Extractors, "query") {
+		p.required("jwt.query_param", c.JWT.QueryParam)
+	}
+	if c.JWT.Cookie.Enable {
+		p.required("jwt.cookie.name", c.JWT.Cookie.Name)
+		p.required("jwt.cookie.refresh_name", c.JWT.Cookie.RefreshName)
+		p.required("jwt.cookie.csrf_name", c.JWT.Cookie.CSRFName)
+		p.oneOf("jwt.cookie.same_site", strings.ToLower(c.JWT.Cookie.SameSite), Choices["jwt.cookie.same_site"]...)
+	}
+
+	// Upload
+	p.positive("upload.size", c.Upload.Size)
+	p.required("upload.dir", c.Upload.Dir)
+
+	// Site
+	p.url("site.url", c.Site.URL)
+	login := c.Site.Login
+	p.nonNegative("site.login.captcha_after", login.CaptchaAfter)
+	p.nonNegative("site.login.max_failures", login.MaxFailures)
+	p.nonNegative("site.login.ip_max_failures", login.IPMaxFailures)
+	if login.MaxFailures > 0 || login.IPMaxFailures > 0 {
+		p.positive("site.login.failure_window", int64(login.FailureWindow))
+		p.positive("site.login.lockout", int64(login.Lockout))
+		if login.MaxLockout < login.Lockout {
+			p.add("site.login.max_lockout", "must not be less than lockout (%d), got %d", login.Lockout, login.MaxLockout)
+		}
+	}
+
+	// Mail
+	p.oneOf("mail.driver", string(c.Mail.Driver), Choices["mail.driver"]...)
+	switch c.Mail.Driver {
+	case SMTP:
+		p.required("mail.host", c.Mail.Host)
+		p.port("mail.port", c.Mail.Port)
+	case OUTBOX:
+		p.required("mail.outbox", c.Mail.Outbox)
+	}
+	p.required("mail.from", c.Mail.From)
+
+	// OIDC
+	if c.OIDC.Enable {
+		p.url("oidc.issuer", c.OIDC.Issuer)
+		p.required("oidc.client_id", c.OIDC.ClientID)
+		p.url("oidc.redirect_url", c.OIDC.RedirectURL)
+		if !slices.Contains(c.OIDC.Scopes, "openid") {
+			p.add("oidc.scopes", "must include openid")
+		}
+		p.positive("oidc.role_id", int64(c.OIDC.RoleID))
+	}
+
+	// Audit
+	p.nonNegative("audit.retention", c.Audit.Retention)
+	p.nonNegative("audit.buffer", c.Audit.Buffer)
+	if c.Audit.Retention > 0 {
+		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+		if _, err := parser.Parse(c.Audit.Cron); err != nil {
+			p.add("audit.cron", "%s", err)
+		}
+	}
+
+	// Password
+	p.oneOf("password.hasher", string(c.Password.Hasher), Choices["password.hasher"]...)
+	switch c.Password.Hasher {
+	case BCRYPT:
+		if c.Password.BcryptCost < 4 || c.Password.BcryptCost > 31 {
+			p.add("password.bcrypt_cost", "must be within 4-31, got %d", c.Password.BcryptCost)
+		}
+	case ARGON2ID:
+		argon := c.Password.Argon2
+		p.positive("password.argon2.memory", int64(argon.Memory))
+		p.positive("password.argon2.iterations", int64(argon.Iterations))
+		p.positive("password.argon2.parallelism", int64(argon.Parallelism))
+		if argon.SaltLength < 8 {
+			p.add("password.argon2.salt_length", "must be at least 8, got %d", argon.SaltLength)
+		}
+		if argon.KeyLength < 16 {
+			p.add("password.argon2.key_length", "must be at least 16, got %d", argon.KeyLength)
+		}
+	}
+	policy := c.Password.Policy
+	p.positive("password.policy.min_length", int64(policy.MinLength))
+	p.nonNegative("password.policy.max_length", policy.MaxLength)
+	if policy.MaxLength > 0 && policy.MaxLength < policy.MinLength {
+		p.add("password.policy.max_length", "must not be less than min_length (%d), got %d", policy.MinLength, policy.MaxLength)
+	}
+	if policy.MinClasses < 0 || policy.MinClasses > 4 {
+		p.add("password.policy.min_classes", "must be within 0-4, got %d", policy.MinClasses)
+	}
+	if policy.Breached != "" {
+		if _, err := os.Stat(policy.Breached); err != nil {
+			p.add("password.policy.breached", "%s", err)
+		}
+	}
+
+	// Log
+	p.oneOf("log.level", c.Log.Level, Choices["log.level"]...)
+
+	if len(p) > 0 {
+		return p
+	}
+	return nil
+}
+
+// Insecure returns values which are valid but unsafe to run with, most of
+// them only in release mode
+func (c *Config) Insecure() Problems {
+	var p Problems
+	release := c.Gin.Mode == "release"
+
+	if c.JWT.Algorithm == "HS256" && release {
+		if c.JWT.SecretKey == DefaultSecretKey {
+			p.add("jwt.secret_key", "is the default %q, anyone can forge tokens", DefaultSecretKey)
+		} else if len(c.JWT.SecretKey) < 32 {
+			p.add("jwt.secret_key", "is shorter than 32 bytes")
+		}
+	}
+	if c.JWT.Cookie.Enable && !c.JWT.Cookie.Secure && release {
+		p.add("jwt.cookie.secure", "is false, cookies are sent over plain HTTP")
+	}
+	if slices.Contains(c.JWT.Extractors, "query") {
+		p.add("jwt.extractors", "includes query, tokens in URLs end up in logs")
+	}
+	if c.DB.Mode != SQLITE && release && (c.DB.Password == "" || c.DB.Password == "root") {
+		p.add("db.password", "is empty or the default")
+	}
+	if c.DB.TLS.Enable && c.DB.TLS.InsecureSkipVerify {
+		p.add("db.tls.insecure_skip_verify", "is true, the database certificate is not checked")
+	}
+	if c.Redis.TLS.Enable && c.Redis.TLS.InsecureSkipVerify {
+		p.add("redis.tls.insecure_skip_verify", "is true, the Redis certificate is not checked")
+	}
+	if c.OIDC.Enable && c.OIDC.LinkByEmail {
+		p.add("oidc.link_by_email", "is true, only safe if the IdP verifies emails")
+	}
+	if c.Password.Hasher == BCRYPT && c.Password.BcryptCost < 10 {
+		p.add("password.bcrypt_cost", "is below 10")
+	}
+	if c.Password.Policy.MinLength < 8 {
+		p.add("password.policy.min_length", "is below 8")
+	}
+	if release && strings.HasPrefix(c.Site.URL, "http://") {
+		if u, err := url.Parse(c.Site.URL); err == nil && !isLoopback(u.Hostname()) {
+			p.add("site.url", "is not HTTPS, links in emails carry reset tokens")
+		}
+	}
+	return p
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
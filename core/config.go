@@ -4,45 +4,252 @@ import (
 	"fast-gin/config"
 	"fast-gin/flags"
 	"fast-gin/global"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
 )
 
+// LoadConfig reads the file given by -f, later sources win:
+//  1. defaults from config.Default
+//  2. the file, YAML, TOML or JSON by its extension, with ${ENV} and
+//     ${ENV:-default} interpolated
+//  3. the profile overlay chosen by -profile or FASTGIN_PROFILE, deep-merged,
+//     -profile dev reads settings-dev.yaml next to settings.yaml
+//  4. environment variables, FASTGIN_DB_PASSWORD overrides db.password
+//  5. -set key=value flags, in the order given
+//
+// Secret fields holding a file:, env: or enc: reference are then resolved.
+// The result is validated, every problem is reported at once.
 func LoadConfig() (cfg *config.Config, err error) {
-	cfg = new(config.Config)
-	file, err := os.Open(flags.Options.File)
+	if flags.Options.Resource == "config" {
+		runConfig()
+	}
+	cfg, sources, err := readConfig()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if flags.Options.CheckConfig {
+		checkConfig(cfg, err)
+	}
 	if err != nil {
-		logrus.Fatalf("error when reading configuration file: %s", err)
+		logrus.Fatalf("error when loading configuration: %s", err)
+	}
+	logSources(sources)
+	for _, problem := range cfg.Insecure() {
+		logrus.Warnf("Insecure configuration %s", problem)
+	}
+	return
+}
+
+// ConfigFiles returns the base file followed by the profile overlay, if any
+func ConfigFiles() []string {
+	files := []string{flags.Options.File}
+	profile := flags.Options.Profile
+	if profile == "" {
+		profile = os.Getenv(config.ProfileEnv)
+	}
+	if profile != "" {
+		files = append(files, config.ProfileFile(flags.Options.File, profile))
 	}
-	defer func(file *os.File) {
-		err := file.Close()
+	return files
+}
+
+func readConfig() (cfg *config.Config, sources config.Sources, err error) {
+	sources = config.Sources{}
+	known := map[string]bool{}
+	for _, key := range config.Keys() {
+		sources[key] = config.SourceDefault
+		known[key] = true
+	}
+
+	merged := config.NewDocument()
+	for _, file := range ConfigFiles() {
+		layer, missing, err := config.ReadLayer(file)
 		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range missing {
+			logrus.Warnf("Environment variable [%s] referenced in [%s] is not set", name, file)
+		}
+		config.Merge(merged, layer, file, sources)
+		logrus.Infof("Configuration [%s] loaded successfully", file)
+	}
+	for key, source := range sources {
+		if !known[key] {
+			logrus.Warnf("Configuration [%s] in [%s] is not a known key, ignored", key, source)
+			delete(sources, key)
+		}
+	}
+
+	cfg = config.Default()
+	err = merged.Decode(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when decoding YAML: %w", err)
+	}
+
+	applied, err := cfg.ApplyEnv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when applying environment: %w", err)
+	}
+	for _, key := range applied {
+		sources[key] = config.SourceEnv
+	}
 
+	for _, set := range flags.Options.Set {
+		key, value, _ := strings.Cut(set, "=")
+		err = cfg.Set(key, value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error when applying -set: %w", err)
 		}
-	}(file)
+		sources[key] = config.SourceFlag
+	}
 
-	decoder := yaml.NewDecoder(file)
-	err = decoder.Decode(&cfg)
+	// Last, so overrides may hold references too
+	resolved, err := cfg.ResolveSecrets()
 	if err != nil {
-		logrus.Fatalf("error when decoding YAML: %s", err)
+		return nil, nil, fmt.Errorf("error when resolving secrets: %w", err)
 	}
-	logrus.Infof("Configuration [%s] loaded successfully", flags.Options.File)
-	return
+	for _, key := range resolved {
+		logrus.Infof("Configuration [%s] resolved from secret reference", key)
+	}
+	return cfg, sources, nil
 }
 
+// logSources logs which source supplied each value, never the values
+func logSources(sources config.Sources) {
+	bySource := map[string][]string{}
+	for _, key := range config.Keys() {
+		bySource[sources[key]] = append(bySource[sources[key]], key)
+	}
+	order := append(ConfigFiles(), config.SourceEnv, config.SourceFlag)
+	for _, source := range order {
+		if keys := bySource[source]; len(keys) > 0 {
+			logrus.Infof("Configuration from [%s]: %s", source, strings.Join(keys, ", "))
+		}
+	}
+	if keys := bySource[config.SourceDefault]; len(keys) > 0 {
+		logrus.Debugf("Configuration from [%s]: %s", config.SourceDefault, strings.Join(keys, ", "))
+	}
+}
+
+// DumpConfig writes the values changed while running back into the file
+// given by -f. ${ENV} references of the other values are kept, and so are
+// comments and layout of YAML files. The previous file is kept as <file>.bak.
 func DumpConfig() error {
-	byteData, err := yaml.Marshal(global.Config)
+	file := flags.Options.File
+	onFile, _, err := readConfig()
+	if err != nil {
+		logrus.Errorf("error when dumping configuration: %s", err)
+		return err
+	}
+	cfg := global.Config()
+	changed := config.Changed(onFile, cfg)
+	if len(changed) == 0 {
+		logrus.Infof("Configuration [%s] is up to date", file)
+		return nil
+	}
+
+	format, err := config.FormatOf(file)
+	if err != nil {
+		logrus.Errorf("error when dumping configuration: %s", err)
+		return err
+	}
+	byteData, err := os.ReadFile(file)
+	if err != nil {
+		logrus.Errorf("error when dumping configuration: %s", err)
+		return err
+	}
+	doc, err := config.Parse(format, byteData)
 	if err != nil {
 		logrus.Errorf("error when dumping configuration: %s", err)
 		return err
 	}
-	err = os.WriteFile(flags.Options.File, byteData, 0666)
+	for _, key := range changed {
+		value, _ := cfg.Get(key)
+		err = config.SetNode(doc, key, value)
+		if err != nil {
+			logrus.Errorf("error when dumping configuration: %s", err)
+			return err
+		}
+	}
+
+	byteData, err = config.Encode(format, doc)
+	if err != nil {
+		logrus.Errorf("error when dumping configuration: %s", err)
+		return err
+	}
+	if format == config.YAML {
+		byteData = separateSections(byteData)
+	}
+	err = writeFileAtomic(file, byteData)
 	if err != nil {
 		logrus.Errorf("error when dumping configuration: %s", err)
 		return err
 	}
-	logrus.Infof("Configuration [%s] dumped successfully", flags.Options.File)
+	logrus.Infof("Configuration [%s] dumped successfully, changed: %s", file, strings.Join(changed, ", "))
 	return nil
 }
+
+// separateSections puts back the blank line before every top level key and
+// its comments, which yaml.Node does not keep
+func separateSections(byteData []byte) []byte {
+	lines := strings.Split(string(byteData), "\n")
+	out := make([]string, 0, len(lines)+16)
+	start := 0 // First line of the current run of top level comments
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			if i == 0 || !strings.HasPrefix(lines[i-1], "#") {
+				start = len(out)
+			}
+		} else if line != "" && line[0] != ' ' && line[0] != '-' && i > 0 {
+			if !strings.HasPrefix(lines[i-1], "#") {
+				start = len(out)
+			}
+			if start > 0 && out[start-1] != "" {
+				out = append(out[:start], append([]string{""}, out[start:]...)...)
+			}
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// writeFileAtomic replaces file through a rename, so it is never seen half
+// written, and copies the previous content to <file>.bak. The mode of the
+// previous file is kept.
+func writeFileAtomic(file string, data []byte) error {
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+		previous, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(file+".bak", previous, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
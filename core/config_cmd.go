@@ -0,0 +1,221 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fast-gin/config"
+	"fast-gin/flags"
+	"fast-gin/utils/aes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strings"
+)
+
+// NewMasterKeyEnv holds the key -op rekey re-encrypts secrets with
+const NewMasterKeyEnv = config.EnvPrefix + "NEW_MASTER_KEY"
+
+// runConfig runs -res config -op <operation> and exits, before anything but
+// the logger is set up
+func runConfig() {
+	switch flags.Options.Operation {
+	case "show":
+		showConfig()
+	case "keygen":
+		keygen()
+	case "encrypt":
+		encryptSecret()
+	case "rekey":
+		rekeyFiles()
+	case "schema":
+		schema()
+	default:
+		logrus.Fatalf("Operation [%s] not supported", flags.Options.Operation)
+	}
+	os.Exit(0)
+}
+
+// checkConfig reports the result of -check-config and exits, with 1 if the
+// configuration is invalid
+func checkConfig(cfg *config.Config, err error) {
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, problem := range cfg.Insecure() {
+		fmt.Println("warning:", problem)
+	}
+	fmt.Printf("Configuration [%s] is valid\n", strings.Join(ConfigFiles(), " + "))
+	os.Exit(0)
+}
+
+// showConfig prints the effective configuration with secrets redacted, each
+// value commented with where it came from
+func showConfig() {
+	cfg, sources, err := readConfig()
+	if err != nil {
+		logrus.Fatalf("error when loading configuration: %s", err)
+	}
+	var node yaml.Node
+	err = node.Encode(cfg.Redact())
+	if err != nil {
+		logrus.Fatalf("error when showing configuration: %s", err)
+	}
+	annotate(&node, "", sources)
+	byteData, err := config.Encode(config.YAML, &node)
+	if err != nil {
+		logrus.Fatalf("error when showing configuration: %s", err)
+	}
+	fmt.Print(string(byteData))
+}
+
+func annotate(mapping *yaml.Node, prefix string, sources config.Sources) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if value.Kind == yaml.MappingNode {
+			annotate(value, path, sources)
+			continue
+		}
+		if value.Kind == yaml.SequenceNode && scalars(value) {
+			value.Style = yaml.FlowStyle
+		}
+		if value.Kind == yaml.SequenceNode && value.Style != yaml.FlowStyle {
+			key.LineComment = sources[path]
+			continue
+		}
+		value.LineComment = sources[path]
+	}
+}
+
+func scalars(seq *yaml.Node) bool {
+	for _, item := range seq.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// keygen prints a new master key
+func keygen() {
+	key, err := aes.GenerateKey()
+	if err != nil {
+		logrus.Fatalf("Failed to generate master key: %s", err)
+	}
+	fmt.Println(key)
+}
+
+// encryptSecret reads a value from the terminal, or stdin when piped, and
+// prints its enc: reference to paste into a settings file
+func encryptSecret() {
+	key, err := config.MasterKey()
+	if err != nil {
+		logrus.Fatalf("Failed to read master key: %s", err)
+	}
+
+	var plain []byte
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, "Please input value to encrypt: ")
+		plain, err = terminal.ReadPassword(int(os.Stdin.Fd()))
+	} else {
+		plain, err = io.ReadAll(os.Stdin)
+		plain = bytes.TrimRight(plain, "\r\n")
+	}
+	if err != nil {
+		logrus.Fatalf("Failed to read value: %s", err)
+	}
+
+	ref, err := config.EncryptSecret(key, string(plain))
+	if err != nil {
+		logrus.Fatalf("Failed to encrypt value: %s", err)
+	}
+	fmt.Println(ref)
+}
+
+// rekeyFiles re-encrypts every enc: secret in the configuration files, the
+// one given by -f and its profile overlay, from the current master key to the
+// one in FASTGIN_NEW_MASTER_KEY. Nothing is written unless every file could
+// be re-keyed.
+func rekeyFiles() {
+	oldKey, err := config.MasterKey()
+	if err != nil {
+		logrus.Fatalf("Failed to read master key: %s", err)
+	}
+	newKey, err := aes.ParseKey(os.Getenv(NewMasterKeyEnv))
+	if err != nil {
+		logrus.Fatalf("Failed to read new master key from %s: %s", NewMasterKeyEnv, err)
+	}
+
+	rekeyed := map[string][]byte{}
+	var files []string
+	for _, file := range ConfigFiles() {
+		byteData, count, err := rekeyFile(file, oldKey, newKey)
+		if err != nil {
+			logrus.Fatalf("Failed to re-key [%s], nothing written: %s", file, err)
+		}
+		if count == 0 {
+			fmt.Printf("No encrypted secrets in [%s]\n", file)
+			continue
+		}
+		fmt.Printf("%d secrets in [%s] re-encrypted\n", count, file)
+		rekeyed[file] = byteData
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	for _, file := range files {
+		err = writeFileAtomic(file, rekeyed[file])
+		if err != nil {
+			logrus.Fatalf("Failed to write [%s]: %s", file, err)
+		}
+		fmt.Printf("[%s] written, the previous file is kept as [%s.bak]\n", file, file)
+	}
+	fmt.Printf("Set %s to the new key before restarting\n", config.MasterKeyEnv)
+}
+
+// rekeyFile returns file with its secrets re-encrypted, and how many there
+// were
+func rekeyFile(file string, oldKey, newKey []byte) ([]byte, int, error) {
+	format, err := config.FormatOf(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	byteData, err := os.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc, err := config.Parse(format, byteData)
+	if err != nil {
+		return nil, 0, err
+	}
+	count, err := config.Rekey(doc, oldKey, newKey)
+	if err != nil || count == 0 {
+		return nil, count, err
+	}
+	byteData, err = config.Encode(format, doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if format == config.YAML {
+		byteData = separateSections(byteData)
+	}
+	return byteData, count, nil
+}
+
+// schema prints the JSON Schema of the configuration
+func schema() {
+	byteData, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		logrus.Fatalf("Failed to generate schema: %s", err)
+	}
+	fmt.Println(string(byteData))
+}
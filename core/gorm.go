@@ -2,6 +2,7 @@ package core
 
 import (
 	"fast-gin/global"
+	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -9,39 +10,60 @@ import (
 )
 
 func InitGorm() (db *gorm.DB) {
-	cfg := global.Config.DB
+	cfg := global.Config().DB
 
 	dialector := cfg.GetDSN()
 	if dialector == nil {
 		return
 	}
 
+	// Retry on start, the database may come up after us
+	backoff := time.Duration(cfg.MinRetryBackoff) * time.Millisecond
+	maxBackoff := time.Duration(cfg.MaxRetryBackoff) * time.Millisecond
+	var err error
+	for attempt := 0; ; attempt++ {
+		db, err = openGorm(dialector)
+		if err == nil || attempt >= cfg.MaxRetries {
+			break
+		}
+		logrus.Warnf("%s, retrying in %s (%d/%d)", err, backoff, attempt+1, cfg.MaxRetries)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+	if err != nil {
+		logrus.Fatalf("%s", err)
+	}
+
+	// Configure DB connection pool
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+
+	logrus.Infof("DB initialized successfully")
+	return db
+}
+
+// openGorm opens a session and probes it
+func openGorm(dialector gorm.Dialector) (*gorm.DB, error) {
 	// Open initialize db session based on dialector
 	db, err := gorm.Open(dialector, &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
 	if err != nil {
-		logrus.Fatalf("Failed to connect to database: %v", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Get DB connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
-		logrus.Fatalf("Failed to get database connection pool: %s", err)
-		return
+		return nil, fmt.Errorf("failed to get database connection pool: %w", err)
 	}
 	err = sqlDB.Ping()
 	if err != nil {
-		logrus.Fatalf("Failed to probe database connection pool liveness: %s", err)
-		return
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("failed to probe database connection pool liveness: %w", err)
 	}
-
-	// Configure DB connection pool
-	// TODO: Add to configuration file
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-
-	logrus.Infof("DB initialized successfully")
-	return db
+	return db, nil
 }
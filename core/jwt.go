@@ -0,0 +1,32 @@
+package core
+
+import (
+	"fast-gin/config"
+	"fast-gin/global"
+	"fast-gin/utils/jwts"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"reflect"
+)
+
+func InitJWT() {
+	err := jwts.LoadKeys(global.Config().JWT)
+	if err != nil {
+		logrus.Fatalf("Failed to load JWT keys: %s", err)
+	}
+	logrus.Infof("JWT keys loaded successfully")
+
+	global.Prepare(func(prev, next *config.Config) (func(), error) {
+		if reflect.DeepEqual(prev.JWT, next.JWT) {
+			return nil, nil
+		}
+		apply, err := jwts.PrepareKeys(next.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+		}
+		return func() {
+			apply()
+			logrus.Infof("JWT keys reloaded successfully")
+		}, nil
+	})
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"bytes"
+	"fast-gin/config"
+	"fast-gin/global"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
@@ -150,3 +152,24 @@ func InitLogger() {
 		logPath: "logs",
 	})
 }
+
+// InitLogLevel applies log.level, InitLogger logs everything until the
+// configuration is loaded
+func InitLogLevel() {
+	setLogLevel(global.Config().Log.Level)
+	global.Subscribe(func(prev, next *config.Config) {
+		if prev.Log.Level != next.Log.Level {
+			setLogLevel(next.Log.Level)
+		}
+	})
+}
+
+func setLogLevel(level string) {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		logrus.Errorf("Invalid log level [%s]: %s", level, err)
+		return
+	}
+	logrus.SetLevel(lvl)
+	logrus.Infof("Log level [%s] applied", lvl)
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"fast-gin/config"
+	"fast-gin/global"
+	"fast-gin/utils/pwd"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"reflect"
+)
+
+func InitPassword() {
+	err := pwd.Configure(global.Config().Password)
+	if err != nil {
+		logrus.Fatalf("Failed to configure password hashing: %s", err)
+	}
+	logrus.Infof("Password hasher [%s] configured successfully", global.Config().Password.Hasher)
+
+	global.Prepare(func(prev, next *config.Config) (func(), error) {
+		if reflect.DeepEqual(prev.Password, next.Password) {
+			return nil, nil
+		}
+		apply, err := pwd.Prepare(next.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure password hashing: %w", err)
+		}
+		return func() {
+			apply()
+			logrus.Infof("Password hasher [%s] reconfigured successfully", next.Password.Hasher)
+		}, nil
+	})
+}
@@ -8,14 +8,13 @@ import (
 )
 
 func InitRedis() *redis.Client {
-	cfg := global.Config
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Addr,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
+	options, err := global.Config().Redis.Options()
+	if err != nil {
+		logrus.Fatalf("Failed to load redis TLS configuration: %s", err)
+	}
+	rdb := redis.NewClient(options)
 
-	_, err := rdb.Ping(context.Background()).Result()
+	_, err = rdb.Ping(context.Background()).Result()
 	if err != nil {
 		logrus.Errorf("Failed to connect to redis: %s", err)
 		return nil
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"fast-gin/config"
+	"fast-gin/global"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Files are polled rather than watched, editors often replace a file instead
+// of writing it, which drops a watch on it
+const watchInterval = 2 * time.Second
+
+var reloadMu sync.Mutex
+
+// ReloadConfig reads and validates the configuration again and swaps it in,
+// notifying subscribers. The current configuration is kept if the new one is
+// invalid, changes a key which cannot change while running or is rejected by
+// a preparer.
+func ReloadConfig() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cfg, _, err := readConfig()
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		logrus.Errorf("Configuration reload rejected: %s", err)
+		return err
+	}
+
+	changed := config.Changed(global.Config(), cfg)
+	if len(changed) == 0 {
+		logrus.Infof("Configuration reloaded, nothing changed")
+		return nil
+	}
+	var immutable config.Problems
+	for _, key := range changed {
+		if reason := config.ImmutableReason(key); reason != "" {
+			immutable = append(immutable, config.Problem{
+				Path: key,
+				Msg:  fmt.Sprintf("cannot change while running, %s, restart to apply", reason),
+			})
+		}
+	}
+	if len(immutable) > 0 {
+		logrus.Errorf("Configuration reload rejected: %s", immutable)
+		return immutable
+	}
+
+	err = global.SetConfig(cfg)
+	if err != nil {
+		logrus.Errorf("Configuration reload rejected: %s", err)
+		return err
+	}
+	for _, problem := range cfg.Insecure() {
+		logrus.Warnf("Insecure configuration %s", problem)
+	}
+	logrus.Infof("Configuration reloaded, changed: %s", strings.Join(changed, ", "))
+	return nil
+}
+
+// WatchConfig reloads the configuration on SIGHUP and whenever one of its
+// files changes
+func WatchConfig() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	ticker := time.NewTicker(watchInterval)
+	stamp := configStamp()
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				logrus.Infof("SIGHUP received, reloading configuration")
+			case <-ticker.C:
+				next := configStamp()
+				if next == stamp {
+					continue
+				}
+				stamp = next
+				logrus.Infof("Configuration file changed, reloading configuration")
+			}
+			_ = ReloadConfig()
+		}
+	}()
+	logrus.Infof("Watching configuration [%s]", strings.Join(ConfigFiles(), " + "))
+}
+
+// configStamp changes whenever a configuration file is written, created or
+// removed
+func configStamp() string {
+	var b strings.Builder
+	for _, file := range ConfigFiles() {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:missing;", file)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String()
+}
@@ -8,10 +8,43 @@ import (
 )
 
 func MigrateDB() {
-	err := global.DB.AutoMigrate(&models.UserModel{})
+	err := global.DB.AutoMigrate(
+		&models.UserModel{},
+		&models.RoleModel{},
+		&models.PermissionModel{},
+		&models.RolePermissionModel{},
+		&models.RecoveryCodeModel{},
+		&models.APIKeyModel{},
+		&models.UserIdentityModel{},
+		&models.AuditLogModel{},
+	)
 	if err != nil {
 		logrus.Errorf("Failed to migrate database: %s", err)
 		return
 	}
+	seedRoles()
 	logrus.Infof("Migrate database successfully")
 }
+
+// seedRoles creates the built-in roles if missing
+func seedRoles() {
+	roles := []models.RoleModel{
+		{Model: models.Model{ID: uint(models.AdminRoleID)}, Name: "admin", Description: "Administrator"},
+		{Model: models.Model{ID: uint(models.UserRoleID)}, Name: "user", Description: "Normal user"},
+	}
+	for _, role := range roles {
+		err := global.DB.Where("id = ?", role.ID).FirstOrCreate(&role).Error
+		if err != nil {
+			logrus.Errorf("Failed to seed role [%s]: %s", role.Name, err)
+		}
+	}
+
+	// Rows inserted with an explicit id do not advance the Postgres
+	// sequence, the next role would be given id 1 again
+	if global.DB.Dialector.Name() == "postgres" {
+		err := global.DB.Exec("SELECT setval(pg_get_serial_sequence('role_models', 'id'), (SELECT MAX(id) FROM role_models))").Error
+		if err != nil {
+			logrus.Errorf("Failed to reset the role id sequence: %s", err)
+		}
+	}
+}
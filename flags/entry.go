@@ -6,24 +6,46 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
+	"strings"
 )
 
 type FlagOptions struct {
-	File      string
-	Version   bool
-	DB        bool
-	Resource  string // user
-	Operation string // create, list, remove
+	File        string
+	Profile     string // Overlay merged over File, dev reads settings-dev.yaml
+	Version     bool
+	CheckConfig bool // Validate configuration and exit
+	DB          bool
+	Resource    string // user
+	Operation   string // create, list, remove
+	Set         Sets   // key=value, overrides configuration file and environment
+}
+
+// Sets collects the repeatable -set key=value flag
+type Sets []string
+
+func (s *Sets) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *Sets) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*s = append(*s, value)
+	return nil
 }
 
 var Options FlagOptions
 
 func Parse() {
 	flag.StringVar(&Options.File, "f", "./config/settings.yaml", "Configuration file")
-	flag.StringVar(&Options.Resource, "res", "", "Resource: [user]")
-	flag.StringVar(&Options.Operation, "op", "", "Operation: [create|list|remove]")
+	flag.StringVar(&Options.Profile, "profile", "", "Configuration profile overlay, defaults to $FASTGIN_PROFILE")
+	flag.StringVar(&Options.Resource, "res", "", "Resource: [user|config]")
+	flag.StringVar(&Options.Operation, "op", "", "Operation: [create|list|remove] for user, [show|keygen|encrypt|rekey|schema] for config")
 	flag.BoolVar(&Options.Version, "v", false, "Print version information")
+	flag.BoolVar(&Options.CheckConfig, "check-config", false, "Validate configuration and exit")
 	flag.BoolVar(&Options.DB, "db", false, "Database migration")
+	flag.Var(&Options.Set, "set", "Override configuration: key=value, repeatable")
 	flag.Parse()
 }
 
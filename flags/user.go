@@ -3,6 +3,8 @@ package flags
 import (
 	"fast-gin/global"
 	"fast-gin/models"
+	"fast-gin/service/svc_audit"
+	"fast-gin/service/svc_user"
 	"fast-gin/utils/pwd"
 	"fmt"
 	"github.com/sirupsen/logrus"
@@ -18,14 +20,25 @@ func (User) Create() {
 	var user models.UserModel
 
 	// Role
-	fmt.Println("Please select a role for user (1 (admin) 2 (normal)): ")
+	var roleList []models.RoleModel
+	global.DB.Order("id").Find(&roleList)
+	if len(roleList) == 0 {
+		fmt.Println("No role found, please migrate database first")
+		return
+	}
+	fmt.Println("Please select a role for user: ")
+	for _, role := range roleList {
+		fmt.Printf("  %d (%s)\n", role.ID, role.Name)
+	}
 	_, err := fmt.Scanln(&user.RoleID)
 	if err != nil {
 		fmt.Println("Input error:", err)
 		return
 	}
-	if user.RoleID != 1 && user.RoleID != 2 {
-		fmt.Println("Role err:", err)
+	var role models.RoleModel
+	err = global.DB.Take(&role, user.RoleID).Error
+	if err != nil {
+		fmt.Println("Role does not exist")
 		return
 	}
 
@@ -63,22 +76,34 @@ func (User) Create() {
 		fmt.Println("Password mismatched")
 		return
 	}
+	if err = pwd.CheckPolicy(string(password)); err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	// Persist
 	encryptedPassword, err := pwd.Encrypt(string(password))
 	if err != nil {
 		fmt.Println("Failed to encrypt password:", err)
 	}
-	err = global.DB.Create(&models.UserModel{
+	user = models.UserModel{
 		Username: user.Username,
 		Password: encryptedPassword,
 		RoleID:   user.RoleID,
-	}).Error
+	}
+	err = global.DB.Create(&user).Error
 	if err != nil {
 		logrus.Errorf("Failed to create user: %s", err)
 		return
 	}
 	logrus.Infof("Create user [%s] successfully", user.Username)
+	svc_audit.Record(models.AuditLogModel{
+		ActorName:  svc_audit.CLIActor,
+		Action:     svc_audit.ActionUserCreate,
+		TargetType: "user",
+		TargetID:   fmt.Sprint(user.ID),
+		Diff:       svc_audit.Diff(nil, user),
+	})
 }
 
 // List Unnamed receiver acts like static method
@@ -86,11 +111,12 @@ func (User) List() {
 	var userList []models.UserModel
 	global.DB.Order("created_at desc").Limit(10).Find(&userList)
 	for _, model := range userList {
-		fmt.Printf("UserID: %d  Username: %s Nickname: %s Role: %d CreatedAt: %s\n",
+		fmt.Printf("UserID: %d  Username: %s Nickname: %s Role: %d Status: %d CreatedAt: %s\n",
 			model.ID,
 			model.Username,
 			model.Nickname,
 			model.RoleID,
+			model.Status,
 			model.CreatedAt.Format("2006-01-02 15:04:05"),
 		)
 	}
@@ -99,6 +125,7 @@ func (User) List() {
 // Remove Unnamed receiver acts like static method
 func (User) Remove() {
 	var username string
+	var u models.UserModel
 
 	// Username
 	for {
@@ -108,12 +135,15 @@ func (User) Remove() {
 			fmt.Println("Input error:", err)
 			return
 		}
-		var u models.UserModel
 		err = global.DB.Take(&u, "username = ?", username).Error
 		if err != nil {
 			fmt.Println("User does not exist")
 			continue
 		}
+		if svc_user.IsLastAdmin(u) {
+			fmt.Println("Cannot delete the last admin")
+			return
+		}
 		break
 	}
 
@@ -125,4 +155,11 @@ func (User) Remove() {
 		return
 	}
 	logrus.Infof("Delete user [%s] successfully", username)
+	svc_audit.Record(models.AuditLogModel{
+		ActorName:  svc_audit.CLIActor,
+		Action:     svc_audit.ActionUserRemove,
+		TargetType: "user",
+		TargetID:   fmt.Sprint(u.ID),
+		Diff:       svc_audit.Diff(u, nil),
+	})
 }
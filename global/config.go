@@ -0,0 +1,77 @@
+package global
+
+import (
+	"fast-gin/config"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber applies a new configuration, prev is the one it replaced
+type Subscriber func(prev, next *config.Config)
+
+// Preparer builds the state a new configuration needs without applying it,
+// an error rejects the configuration. apply, when not nil, is called once
+// next is swapped in, before subscribers.
+type Preparer func(prev, next *config.Config) (apply func(), err error)
+
+var (
+	current       atomic.Pointer[config.Config]
+	subscribersMu sync.Mutex
+	subscribers   []Subscriber
+	preparers     []Preparer
+)
+
+// Config returns the current configuration. It is replaced as a whole on
+// reload, so keep the returned pointer to read several values consistently.
+func Config() *config.Config {
+	return current.Load()
+}
+
+// SetConfig replaces the configuration and notifies subscribers in the order
+// they subscribed. The configuration is kept, and the error of the preparer
+// returned, when a preparer rejects cfg. The first configuration is set as it
+// is, the Init functions build what it needs.
+func SetConfig(cfg *config.Config) error {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	prev := current.Load()
+	if prev == nil {
+		current.Store(cfg)
+		return nil
+	}
+
+	var applies []func()
+	for _, fn := range preparers {
+		apply, err := fn(prev, cfg)
+		if err != nil {
+			return err
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+	current.Store(cfg)
+	for _, apply := range applies {
+		apply()
+	}
+	for _, fn := range subscribers {
+		fn(prev, cfg)
+	}
+	return nil
+}
+
+// Subscribe calls fn whenever the configuration is replaced. Values read from
+// Config() on every use need no subscriber, it is for state built from them.
+func Subscribe(fn Subscriber) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Prepare calls fn before the configuration is replaced, for state which can
+// fail to build from it, such as keys read from files
+func Prepare(fn Preparer) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	preparers = append(preparers, fn)
+}
@@ -1,7 +1,6 @@
 package global
 
 import (
-	"fast-gin/config"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -9,7 +8,6 @@ import (
 const VERSION = "0.0.1"
 
 var (
-	Config *config.Config
-	DB     *gorm.DB
-	Redis  *redis.Client
+	DB    *gorm.DB
+	Redis *redis.Client
 )
@@ -5,6 +5,8 @@ import (
 	"fast-gin/flags"
 	"fast-gin/global"
 	"fast-gin/routers"
+	"fast-gin/service/svc_audit"
+	"fast-gin/service/svc_cron"
 	"fmt"
 )
 
@@ -16,11 +18,18 @@ func main() {
 	flags.Parse()
 
 	// Configuration
-	var err error
-	global.Config, err = core.LoadConfig()
+	cfg, err := core.LoadConfig()
 	if err != nil {
 		panic(err)
 	}
+	global.SetConfig(cfg)
+	core.InitLogLevel()
+
+	// JWT
+	core.InitJWT()
+
+	// Password hashing and policy
+	core.InitPassword()
 
 	// GORM
 	global.DB = core.InitGorm()
@@ -31,11 +40,20 @@ func main() {
 	// Handle DB migration and version print
 	flags.Run()
 
+	// Audit log writer (goroutine)
+	svc_audit.Start()
+
 	// Cron (goroutine)
-	//svc_cron.CronInit()
+	svc_cron.CronInit()
 
-	// Gin
+	// Configuration hot reload (goroutine)
+	core.WatchConfig()
+
+	// Gin, returns on SIGINT or SIGTERM
 	routers.Run()
 
+	// Store audit log entries still queued
+	svc_audit.Stop()
+
 	fmt.Println("End of Main")
 }
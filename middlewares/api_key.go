@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"fast-gin/service/svc_apikey"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const apiKeyScheme = "ApiKey "
+
+// APIKeyAuthMiddleware accepts an API key in the `Authorization: ApiKey ...`
+// header, and falls back to AuthMiddleware otherwise. Routes using it should
+// declare their permissions, which bound what a key can do.
+func APIKeyAuthMiddleware(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, apiKeyScheme) {
+		AuthMiddleware(c)
+		return
+	}
+
+	key, user, err := svc_apikey.Authenticate(strings.TrimSpace(header[len(apiKeyScheme):]), c.ClientIP())
+	if err != nil {
+		response.FailWithMsg(c, "Authentication failed")
+		c.Abort()
+		return
+	}
+
+	// Set claim in context
+	c.Set(claimsKey, &jwts.CustomClaims{
+		ClaimMeta: jwts.ClaimMeta{
+			UserID:   user.ID,
+			RoleID:   user.RoleID,
+			APIKeyID: key.ID,
+			Scopes:   key.Scopes,
+		},
+	})
+	c.Next()
+}
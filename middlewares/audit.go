@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"fast-gin/models"
+	"fast-gin/service/svc_audit"
+	"fast-gin/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const auditKey = "audit"
+
+// AuditMiddleware records action once the request is handled. The target
+// type is the part of action before the dot and the target ID the :id
+// parameter or the user of the request, unless the view sets them with
+// AuditTarget.
+func AuditMiddleware(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		entry := *auditEntry(c)
+		entry.Action = action
+		if entry.TargetType == "" {
+			entry.TargetType, _, _ = strings.Cut(action, ".")
+		}
+		if entry.TargetID == "" {
+			entry.TargetID = c.Param("id")
+		}
+		if claims, ok := GetClaims(c); ok {
+			if entry.TargetID == "" {
+				// Self-service routes act on the user
+				entry.TargetID = fmt.Sprint(claims.UserID)
+			}
+			entry.UserID = claims.UserID
+			if claims.Actor != nil {
+				entry.ActorID = claims.Actor.UserID
+				entry.ActorName = claims.Actor.Username
+			} else if entry.ActorID == 0 {
+				entry.ActorID = claims.UserID
+			}
+		}
+		entry.Status = c.Writer.Status()
+		entry.Result = models.AuditResultSuccess
+		if res, ok := response.Written(c); ok && res.Code != 0 {
+			entry.Result = models.AuditResultFailure
+			entry.Detail = strings.TrimPrefix(entry.Detail+"; "+res.Msg, "; ")
+		}
+		svc_audit.Record(svc_audit.FromRequest(c, entry))
+	}
+}
+
+func auditEntry(c *gin.Context) *models.AuditLogModel {
+	if value, ok := c.Get(auditKey); ok {
+		if entry, ok := value.(*models.AuditLogModel); ok {
+			return entry
+		}
+	}
+	entry := new(models.AuditLogModel)
+	c.Set(auditKey, entry)
+	return entry
+}
+
+// AuditActor names the actor of a request made without a token, e.g. login
+func AuditActor(c *gin.Context, userID uint, name string) {
+	entry := auditEntry(c)
+	entry.ActorID = userID
+	entry.ActorName = name
+	entry.UserID = userID
+}
+
+// AuditTarget names what the audited request acts on
+func AuditTarget(c *gin.Context, targetType string, id any) {
+	entry := auditEntry(c)
+	entry.TargetType = targetType
+	entry.TargetID = fmt.Sprint(id)
+}
+
+// AuditDiff records the state of the target before and after the request
+func AuditDiff(c *gin.Context, before, after any) {
+	auditEntry(c).Diff = svc_audit.Diff(before, after)
+}
+
+// AuditDetail adds free text to the audit entry of the request
+func AuditDetail(c *gin.Context, detail string) {
+	auditEntry(c).Detail = detail
+}
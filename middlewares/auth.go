@@ -1,64 +1,147 @@
 package middlewares
 
 import (
+	"fast-gin/models"
 	"fast-gin/service/svc_redis"
+	"fast-gin/service/svc_user"
 	"fast-gin/utils/jwts"
 	"fast-gin/utils/response"
 	"github.com/gin-gonic/gin"
+	"slices"
 )
 
+const claimsKey = "claims"
+
+// AuthMiddleware requires a valid access token and stores its claims, read
+// them with GetClaims
 func AuthMiddleware(c *gin.Context) {
-	token := c.GetHeader("token")
-	_, err := jwts.ValidateJWT(token)
-	if err != nil {
-		response.FailWithMsg(c, "Authentication failed")
-		c.Abort()
+	claims, ok := requireAuth(c)
+	if !ok {
 		return
 	}
-	if svc_redis.HasLoggedOut(token) {
-		response.FailWithMsg(c, "User has logged out")
-		c.Abort()
+	next(c, claims)
+}
+
+// OptionalAuthMiddleware stores the claims when a valid access token is
+// present and lets anonymous requests through, for public routes that
+// personalise their content
+func OptionalAuthMiddleware(c *gin.Context) {
+	if claims, _ := authenticate(c); claims != nil {
+		c.Set(claimsKey, claims)
+		next(c, claims)
 		return
 	}
 	c.Next()
 }
 
-func AdminAuthMiddleware(c *gin.Context) {
-	token := c.GetHeader("token")
-
-	claims, err := jwts.ValidateJWT(token)
-	if err != nil {
-		response.FailWithMsg(c, "Authentication failed")
-		c.Abort()
-		return
+// RoleMiddleware requires one of roles, it must be chained after
+// AuthMiddleware
+func RoleMiddleware(roles ...int8) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			response.FailWithMsg(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if claims.Actor != nil {
+			response.FailWithMsg(c, "Not allowed while impersonating")
+			c.Abort()
+			return
+		}
+		if !slices.Contains(roles, claims.RoleID) {
+			response.FailWithMsg(c, "Role Authentication failed")
+			c.Abort()
+			return
+		}
+		c.Next()
 	}
-	if svc_redis.HasLoggedOut(token) {
-		response.FailWithMsg(c, "User has logged out")
-		c.Abort()
+}
+
+var adminRole = RoleMiddleware(models.AdminRoleID)
+
+// AdminAuthMiddleware is AuthMiddleware followed by an admin role check
+func AdminAuthMiddleware(c *gin.Context) {
+	_, ok := requireAuth(c)
+	if !ok {
 		return
 	}
-	if claims.RoleID != 1 {
-		response.FailWithMsg(c, "Role Authentication failed")
+	adminRole(c)
+}
+
+// requireAuth stores the claims of the request, or aborts it
+func requireAuth(c *gin.Context) (*jwts.CustomClaims, bool) {
+	claims, msg := authenticate(c)
+	if claims == nil {
+		response.FailWithMsg(c, msg)
 		c.Abort()
-		return
+		return nil, false
 	}
 
 	// Set claim in context
-	c.Set("claims", claims)
+	c.Set(claimsKey, claims)
+	return claims, true
+}
+
+// next continues the chain, requests of impersonation tokens are marked
+// and audited
+func next(c *gin.Context, claims *jwts.CustomClaims) {
+	if claims.Actor != nil {
+		impersonating(c, claims)
+		return
+	}
 	c.Next()
 }
 
-func GetClaimsFrom(c *gin.Context) (claims *jwts.CustomClaims) {
-	claims = new(jwts.CustomClaims)
+// authenticate validates the access token of the request, returning the
+// failure message when it is rejected
+func authenticate(c *gin.Context) (*jwts.CustomClaims, string) {
+	token, from := GetToken(c)
+	if token == "" {
+		return nil, "Authentication required"
+	}
+	claims, err := jwts.ValidateJWT(token)
+	if err != nil {
+		return nil, "Authentication failed"
+	}
+	if from == TokenFromCookie && !CheckCSRF(c) {
+		return nil, "CSRF token mismatch"
+	}
+	if svc_redis.HasLoggedOut(token) {
+		return nil, "User has logged out"
+	}
+	if !svc_redis.SessionActive(claims.SessionID) {
+		return nil, "Session has been revoked"
+	}
+	if version, err := svc_user.TokenVersion(claims.UserID); err != nil || version != claims.Version {
+		return nil, "Token has been revoked"
+	}
+	// Revoking the tokens of an admin also ends their impersonations
+	if claims.Actor != nil {
+		if version, err := svc_user.TokenVersion(claims.Actor.UserID); err != nil || version != claims.Actor.Version {
+			return nil, "Token has been revoked"
+		}
+	}
+	return claims, ""
+}
 
-	_claims, ok := c.Get("claims")
+// GetClaims returns the claims stored by an auth middleware, false for
+// anonymous requests
+func GetClaims(c *gin.Context) (*jwts.CustomClaims, bool) {
+	value, ok := c.Get(claimsKey)
 	if !ok {
-		return
+		return nil, false
 	}
+	claims, ok := value.(*jwts.CustomClaims)
+	return claims, ok && claims != nil
+}
 
-	claims, ok = _claims.(*jwts.CustomClaims)
+// GetClaimsFrom returns the stored claims, or empty claims for anonymous
+// requests. Only use it on routes behind AuthMiddleware.
+func GetClaimsFrom(c *gin.Context) (claims *jwts.CustomClaims) {
+	claims, ok := GetClaims(c)
 	if !ok {
-		return
+		return new(jwts.CustomClaims)
 	}
-	return
+	return claims
 }
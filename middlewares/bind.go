@@ -3,6 +3,7 @@ package middlewares
 import (
 	"fast-gin/utils/response"
 	"github.com/gin-gonic/gin"
+	"reflect"
 )
 
 func BindJsonMiddleware[T any](c *gin.Context) {
@@ -13,8 +14,8 @@ func BindJsonMiddleware[T any](c *gin.Context) {
 		c.Abort()
 		return
 	}
-	// Set in context
-	c.Set("request", req)
+	// Set in context, keyed by type so that several binds can be chained
+	c.Set(bindKey[T](), req)
 	return
 }
 
@@ -26,7 +27,7 @@ func BindQueryMiddleware[T any](c *gin.Context) {
 		c.Abort()
 		return
 	}
-	c.Set("request", req)
+	c.Set(bindKey[T](), req)
 	return
 }
 func BindUriMiddleware[T any](c *gin.Context) {
@@ -37,10 +38,14 @@ func BindUriMiddleware[T any](c *gin.Context) {
 		c.Abort()
 		return
 	}
-	c.Set("request", req)
+	c.Set(bindKey[T](), req)
 	return
 }
 
 func GetBind[T any](c *gin.Context) (cr T) {
-	return c.MustGet("request").(T)
+	return c.MustGet(bindKey[T]()).(T)
+}
+
+func bindKey[T any]() string {
+	return "request_" + reflect.TypeFor[T]().String()
 }
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"fast-gin/models"
+	"fast-gin/service/svc_audit"
+	"fast-gin/utils/jwts"
+	"fast-gin/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonatedByHeader is set on every response to an impersonation token
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// impersonating marks the response and records the request in the audit log
+func impersonating(c *gin.Context, claims *jwts.CustomClaims) {
+	c.Header(ImpersonatedByHeader, fmt.Sprintf("%s (%d)", claims.Actor.Username, claims.Actor.UserID))
+	c.Next()
+	result := models.AuditResultSuccess
+	if res, ok := response.Written(c); ok && res.Code != 0 {
+		result = models.AuditResultFailure
+	}
+	svc_audit.Record(svc_audit.FromRequest(c, models.AuditLogModel{
+		ActorID:    claims.Actor.UserID,
+		ActorName:  claims.Actor.Username,
+		UserID:     claims.UserID,
+		Action:     svc_audit.ActionImpersonationRequest,
+		TargetType: "user",
+		TargetID:   fmt.Sprint(claims.UserID),
+		Result:     result,
+		Status:     c.Writer.Status(),
+	}))
+}
+
+// NoImpersonationMiddleware rejects impersonation tokens, for routes that
+// change credentials or security settings of the user
+func NoImpersonationMiddleware(c *gin.Context) {
+	if claims, ok := GetClaims(c); ok && claims.Actor != nil {
+		response.FailWithMsg(c, "Not allowed while impersonating")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -1,8 +1,11 @@
 package middlewares
 
 import (
+	"fast-gin/config"
+	"fast-gin/global"
 	"fast-gin/utils/response"
 	"github.com/gin-gonic/gin"
+	"sync"
 	"time"
 )
 
@@ -10,6 +13,16 @@ func LimitMiddleware(limit int) gin.HandlerFunc {
 	return NewLimiter(limit, 1*time.Second).Middleware
 }
 
+// RateLimitMiddleware allows gin.rate_limit requests per second per IP and
+// follows the configuration when it is reloaded
+func RateLimitMiddleware() gin.HandlerFunc {
+	l := NewLimiter(global.Config().Gin.RateLimit, 1*time.Second)
+	global.Subscribe(func(_, next *config.Config) {
+		l.SetLimit(next.Gin.RateLimit)
+	})
+	return l.Middleware
+}
+
 func NewLimiter(limit int, duration time.Duration) *Limiter {
 	return &Limiter{
 		limit:      limit,
@@ -19,13 +32,22 @@ func NewLimiter(limit int, duration time.Duration) *Limiter {
 }
 
 type Limiter struct {
+	mu         sync.Mutex
 	limit      int                // Limit
 	duration   time.Duration      // Window size
 	timestamps map[string][]int64 // Timestamp
 }
 
+// SetLimit changes the limit, timestamps already recorded are kept
+func (l *Limiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
 func (l *Limiter) Middleware(c *gin.Context) {
 	ip := c.ClientIP()
+	l.mu.Lock()
 
 	// If timestamp of corresponding ip exists
 	if _, ok := l.timestamps[ip]; !ok {
@@ -43,12 +65,14 @@ func (l *Limiter) Middleware(c *gin.Context) {
 	}
 
 	if len(l.timestamps[ip]) >= l.limit {
+		l.mu.Unlock()
 		response.FailWithMsg(c, "Too many requests")
 		c.Abort()
 		return
 	}
 
 	l.timestamps[ip] = append(l.timestamps[ip], now)
+	l.mu.Unlock()
 
 	// Continue
 	c.Next()
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"fast-gin/service/svc_rbac"
+	"fast-gin/utils/response"
+	"github.com/gin-gonic/gin"
+	"slices"
+)
+
+// impersonationAllowed are the codes an impersonation token may use, reads
+// a user needs to work with the application. Anything else may be an admin
+// action, whatever role the impersonated user has.
+var impersonationAllowed = []string{
+	"roles:list",
+}
+
+// PermissionMiddleware declares the permission a route requires, it must be
+// chained after AuthMiddleware or APIKeyAuthMiddleware. Requests
+// authenticated by an API key also need the code among its scopes.
+func PermissionMiddleware(code string) gin.HandlerFunc {
+	svc_rbac.Register(code)
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			response.FailWithMsg(c, "Authentication required")
+			c.Abort()
+			return
+		}
+		if claims.Actor != nil && !slices.Contains(impersonationAllowed, code) {
+			response.FailWithMsg(c, "Not allowed while impersonating")
+			c.Abort()
+			return
+		}
+		if !svc_rbac.HasPermission(claims.RoleID, code) {
+			response.FailWithMsg(c, "Permission denied")
+			c.Abort()
+			return
+		}
+		if claims.APIKeyID != 0 && !slices.Contains(claims.Scopes, code) {
+			response.FailWithMsg(c, "API key is not granted this scope")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,151 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"fast-gin/global"
+	"fast-gin/utils/random"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+const (
+	TokenFromBearer = "bearer"
+	TokenFromHeader = "header"
+	TokenFromQuery  = "query"
+	TokenFromCookie = "cookie"
+
+	CSRFHeader = "X-CSRF-Token"
+)
+
+var defaultExtractors = []string{TokenFromBearer, TokenFromHeader}
+
+var extractors = map[string]func(c *gin.Context) string{
+	TokenFromBearer: func(c *gin.Context) string {
+		header := c.GetHeader("Authorization")
+		if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+			return strings.TrimSpace(header[7:])
+		}
+		return ""
+	},
+	TokenFromHeader: func(c *gin.Context) string {
+		return c.GetHeader("token")
+	},
+	TokenFromQuery: func(c *gin.Context) string {
+		return c.Query(queryParam())
+	},
+	TokenFromCookie: func(c *gin.Context) string {
+		token, _ := c.Cookie(cookieName())
+		return token
+	},
+}
+
+// GetToken returns the access token of the request and where it was found,
+// trying the configured extractors in order
+func GetToken(c *gin.Context) (token string, from string) {
+	names := global.Config().JWT.Extractors
+	if len(names) == 0 {
+		names = defaultExtractors
+	}
+	for _, name := range names {
+		extract, ok := extractors[name]
+		if !ok {
+			continue
+		}
+		if token = extract(c); token != "" {
+			return token, name
+		}
+	}
+	return "", ""
+}
+
+// CheckCSRF verifies the double-submit token of a request authenticated by
+// cookie, the header must repeat the CSRF cookie. Safe methods pass.
+func CheckCSRF(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie, err := c.Cookie(csrfName())
+	header := c.GetHeader(CSRFHeader)
+	if err != nil || cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// GetRefreshTokenCookie returns the refresh token cookie, if any
+func GetRefreshTokenCookie(c *gin.Context) string {
+	token, _ := c.Cookie(refreshName())
+	return token
+}
+
+// SetTokenCookies hands out the tokens as HttpOnly cookies together with a
+// fresh CSRF token, when enabled
+func SetTokenCookies(c *gin.Context, accessToken, refreshToken string) error {
+	cfg := global.Config().JWT
+	if !cfg.Cookie.Enable {
+		return nil
+	}
+	csrf, err := random.Token(32)
+	if err != nil {
+		return err
+	}
+	setCookie(c, cookieName(), accessToken, cfg.Expire*3600, true)
+	if refreshToken != "" {
+		setCookie(c, refreshName(), refreshToken, cfg.RefreshExpire*3600, true)
+	}
+	// Readable by scripts of the site, so they can echo it
+	setCookie(c, csrfName(), csrf, cfg.RefreshExpire*3600, false)
+	return nil
+}
+
+// ClearTokenCookies removes the cookies set by SetTokenCookies
+func ClearTokenCookies(c *gin.Context) {
+	if !global.Config().JWT.Cookie.Enable {
+		return
+	}
+	for _, name := range []string{cookieName(), refreshName(), csrfName()} {
+		setCookie(c, name, "", -1, true)
+	}
+}
+
+func setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	cfg := global.Config().JWT.Cookie
+	switch strings.ToLower(cfg.SameSite) {
+	case "strict":
+		c.SetSameSite(http.SameSiteStrictMode)
+	case "none":
+		c.SetSameSite(http.SameSiteNoneMode)
+	default:
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "/"
+	}
+	c.SetCookie(name, value, maxAge, path, cfg.Domain, cfg.Secure, httpOnly)
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func cookieName() string {
+	return orDefault(global.Config().JWT.Cookie.Name, "access_token")
+}
+
+func refreshName() string {
+	return orDefault(global.Config().JWT.Cookie.RefreshName, "refresh_token")
+}
+
+func csrfName() string {
+	return orDefault(global.Config().JWT.Cookie.CSRFName, "csrf_token")
+}
+
+func queryParam() string {
+	return orDefault(global.Config().JWT.QueryParam, "access_token")
+}
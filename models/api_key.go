@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIKeyModel is a long-lived credential for machine clients, acting as its
+// user within Scopes. Only the SHA-256 of the key is stored.
+type APIKeyModel struct {
+	Model                 // Base
+	UserID     uint       `gorm:"index" json:"userID"`
+	Name       string     `gorm:"size:64" json:"name"`
+	Prefix     string     `gorm:"size:16" json:"prefix"` // Leading characters of the key, to tell keys apart
+	Hash       string     `gorm:"size:64;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"serializer:json" json:"scopes"` // Permission codes
+	ExpiresAt  *time.Time `json:"expiresAt"`                     // Never expires when nil
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `gorm:"size:64" json:"lastUsedIP"`
+}
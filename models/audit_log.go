@@ -0,0 +1,26 @@
+package models
+
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditLogModel records who did what to which target and how it ended.
+// ActorID is 0 for anonymous requests and the command line.
+type AuditLogModel struct {
+	Model             // Base
+	ActorID    uint   `gorm:"index" json:"actorID"`
+	ActorName  string `gorm:"size:32" json:"actorName"`
+	UserID     uint   `gorm:"index" json:"userID"` // User acted as, differs from ActorID when impersonating
+	Action     string `gorm:"size:64;index" json:"action"`
+	TargetType string `gorm:"size:32;index:idx_audit_target" json:"targetType"`
+	TargetID   string `gorm:"size:64;index:idx_audit_target" json:"targetID"`
+	Result     string `gorm:"size:16" json:"result"`
+	Method     string `gorm:"size:8" json:"method"`
+	Path       string `gorm:"size:255" json:"path"`
+	Status     int    `json:"status"`
+	IP         string `gorm:"size:64" json:"ip"`
+	UserAgent  string `gorm:"size:255" json:"userAgent"`
+	Diff       string `gorm:"type:text" json:"diff"` // JSON object of changed fields: {"field": [before, after]}
+	Detail     string `gorm:"type:text" json:"detail"`
+}
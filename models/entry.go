@@ -16,3 +16,7 @@ type PageInfo struct {
 	Key   string `form:"key"`
 	Order string `form:"order"`
 }
+
+type IDRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
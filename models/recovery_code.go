@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RecoveryCodeModel is a one-time code replacing TOTP when the
+// authenticator is lost, only its SHA-256 is stored
+type RecoveryCodeModel struct {
+	Model             // Base
+	UserID uint       `gorm:"index" json:"userID"`
+	Hash   string     `gorm:"size:64;index" json:"-"`
+	UsedAt *time.Time `json:"usedAt"`
+}
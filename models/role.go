@@ -0,0 +1,36 @@
+package models
+
+import "math"
+
+// Built-in roles, seeded by database migration
+const (
+	AdminRoleID int8 = 1 // Granted every permission
+	UserRoleID  int8 = 2
+)
+
+type RoleModel struct {
+	Model              // Base
+	Name        string `gorm:"size:32;unique" json:"name"`
+	Description string `gorm:"size:128" json:"description"`
+}
+
+// RoleID returns the ID as stored in UserModel.RoleID, false when it does
+// not fit in an int8
+func (r RoleModel) RoleID() (int8, bool) {
+	if r.ID == 0 || r.ID > math.MaxInt8 {
+		return 0, false
+	}
+	return int8(r.ID), true
+}
+
+type PermissionModel struct {
+	Model              // Base
+	Code        string `gorm:"size:64;unique" json:"code"` // resource:action, e.g. users:list
+	Description string `gorm:"size:128" json:"description"`
+}
+
+type RolePermissionModel struct {
+	Model             // Base
+	RoleID       uint `gorm:"uniqueIndex:idx_role_permission" json:"roleID"`
+	PermissionID uint `gorm:"uniqueIndex:idx_role_permission" json:"permissionID"`
+}
@@ -1,11 +1,24 @@
 package models
 
+const (
+	UserStatusActive     int8 = 1
+	UserStatusDisabled   int8 = 2
+	UserStatusUnverified int8 = 3 // Registered, email not confirmed yet
+)
+
 type UserModel struct {
 	Model           // Base
 	Username string `gorm:"size:16" json:"username"`
 	Nickname string `gorm:"size:32" json:"nickname"`
-	Password string `gorm:"size:64" json:"-"`
-	RoleID   int8   `json:"roleID"` // 1: admin, 2: normal
+	Email    string `gorm:"size:128;index" json:"email"`
+	Password string `gorm:"size:255" json:"-"`       // PHC string or bcrypt hash
+	RoleID   int8   `json:"roleID"`                  // RoleModel.ID, see AdminRoleID and UserRoleID
+	Status   int8   `gorm:"default:1" json:"status"` // 1: active, 2: disabled, 3: unverified
+
+	TokenVersion uint `json:"-"` // Bumped to revoke every token of the user
+
+	TOTPSecret  string `gorm:"size:64" json:"-"`
+	TOTPEnabled bool   `json:"totpEnabled"`
 
-	// TODO: Email, Phone, UUID, OpenID...
+	// TODO: Phone, UUID... (OpenID logins are linked by UserIdentityModel)
 }
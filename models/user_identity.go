@@ -0,0 +1,11 @@
+package models
+
+// UserIdentityModel links a user to an account at an external identity
+// provider, Subject is unique within Issuer
+type UserIdentityModel struct {
+	Model          // Base
+	UserID  uint   `gorm:"index" json:"userID"`
+	Issuer  string `gorm:"size:255;uniqueIndex:idx_issuer_subject" json:"issuer"`
+	Subject string `gorm:"size:255;uniqueIndex:idx_issuer_subject" json:"subject"`
+	Email   string `gorm:"size:128" json:"email"` // As last reported by the provider
+}
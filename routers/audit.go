@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"fast-gin/apis"
+	"fast-gin/apis/audit"
+	"fast-gin/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func AuditRouter(g *gin.RouterGroup) {
+	auditAPI := apis.Apis.AuditAPI
+
+	r := g.Group("audit-logs").Use(
+		middlewares.AuthMiddleware,
+	)
+
+	r.GET("", middlewares.PermissionMiddleware("audit:list"), middlewares.BindQueryMiddleware[audit.ListRequest], auditAPI.ListView)
+}
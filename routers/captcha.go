@@ -9,8 +9,9 @@ import (
 func CaptchaRouter(g *gin.RouterGroup) {
 	captchaAPI := apis.Apis.CaptchaAPI
 
+	// Public, login and registration depend on it
 	r := g.Group("captcha").Use(
-		middlewares.AdminAuthMiddleware,
+		middlewares.RateLimitMiddleware(),
 	)
 
 	r.GET("generate", captchaAPI.GenerateCaptcha)
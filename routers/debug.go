@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"fast-gin/apis"
+	"fast-gin/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func DebugRouter(g *gin.RouterGroup) {
+	debugAPI := apis.Apis.DebugAPI
+
+	r := g.Group("debug").Use(
+		middlewares.AuthMiddleware,
+	)
+
+	r.GET("pools", middlewares.PermissionMiddleware("debug:pools"), debugAPI.PoolsView)
+}
@@ -1,13 +1,23 @@
 package routers
 
 import (
+	"context"
+	"errors"
 	"fast-gin/global"
+	"fast-gin/service/svc_rbac"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 func Run() {
-	gin.SetMode(global.Config.Gin.Mode)
+	gin.SetMode(global.Config().Gin.Mode)
 
 	r := gin.Default()
 
@@ -15,6 +25,9 @@ func Run() {
 	// curl http://localhost:8080/uploads/test.txt
 	r.Static("/uploads", "./static/uploads")
 
+	// Well-known
+	JWKSRouter(&r.RouterGroup)
+
 	// Grouping routes
 	v1 := r.Group("v1")
 
@@ -23,13 +36,37 @@ func Run() {
 
 	// Biz
 	UserRouter(v1)
+	MeRouter(v1)
 	ImageRouter(v1)
 	CaptchaRouter(v1)
+	RoleRouter(v1)
+	AuditRouter(v1)
+
+	// Diagnostics
+	DebugRouter(v1)
+
+	// Persist permissions declared by routes
+	svc_rbac.SyncPermissions()
+
+	// Run Gin server until SIGINT or SIGTERM, then let requests in flight
+	// finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Addr: global.Config().Gin.Addr(), Handler: r}
+	go func() {
+		logrus.Infof("Listening and serving HTTP on %s", srv.Addr)
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("Failed to start Gin server: %v", err)
+		}
+	}()
+	<-ctx.Done()
 
-	// Run Gin server
-	err := r.Run(global.Config.Gin.Addr())
+	logrus.Infof("Shutting down Gin server")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(ctx)
 	if err != nil {
-		logrus.Fatalf("Failed to start Gin server: %v", err)
-		return
+		logrus.Errorf("Failed to shut down Gin server: %v", err)
 	}
 }
@@ -3,6 +3,7 @@ package routers
 import (
 	"fast-gin/apis"
 	"fast-gin/middlewares"
+	"fast-gin/service/svc_audit"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,8 +11,8 @@ func ImageRouter(g *gin.RouterGroup) {
 	imageAPI := apis.Apis.ImageAPI
 
 	r := g.Group("images").Use(
-		middlewares.AdminAuthMiddleware,
+		middlewares.APIKeyAuthMiddleware,
 	)
 
-	r.POST("upload", imageAPI.UploadView)
+	r.POST("upload", middlewares.PermissionMiddleware("images:upload"), middlewares.AuditMiddleware(svc_audit.ActionImageUpload), imageAPI.UploadView)
 }
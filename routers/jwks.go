@@ -0,0 +1,12 @@
+package routers
+
+import (
+	"fast-gin/apis"
+	"github.com/gin-gonic/gin"
+)
+
+func JWKSRouter(g *gin.RouterGroup) {
+	jwksAPI := apis.Apis.JWKSAPI
+
+	g.GET("/.well-known/jwks.json", jwksAPI.JWKSView)
+}
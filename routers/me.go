@@ -0,0 +1,34 @@
+package routers
+
+import (
+	"fast-gin/apis"
+	"fast-gin/apis/me"
+	"fast-gin/middlewares"
+	"fast-gin/service/svc_audit"
+	"github.com/gin-gonic/gin"
+)
+
+func MeRouter(g *gin.RouterGroup) {
+	meAPI := apis.Apis.MeAPI
+
+	r := g.Group("me").Use(
+		middlewares.AuthMiddleware,
+	)
+
+	r.GET("", meAPI.ProfileView)
+	r.PUT("", middlewares.BindJsonMiddleware[me.UpdateProfileRequest], meAPI.UpdateProfileView)
+	r.PUT("password", middlewares.NoImpersonationMiddleware, middlewares.AuditMiddleware(svc_audit.ActionPasswordChange), middlewares.BindJsonMiddleware[me.ChangePasswordRequest], meAPI.ChangePasswordView)
+	r.GET("sessions", meAPI.SessionListView)
+	r.DELETE("sessions/:id", middlewares.NoImpersonationMiddleware, middlewares.BindUriMiddleware[me.SessionRequest], meAPI.SessionRevokeView)
+
+	// API keys
+	r.GET("api-keys", meAPI.APIKeyListView)
+	r.POST("api-keys", middlewares.NoImpersonationMiddleware, middlewares.AuditMiddleware(svc_audit.ActionAPIKeyCreate), middlewares.BindJsonMiddleware[me.APIKeyCreateRequest], meAPI.APIKeyCreateView)
+	r.DELETE("api-keys/:id", middlewares.NoImpersonationMiddleware, middlewares.AuditMiddleware(svc_audit.ActionAPIKeyRevoke), middlewares.BindUriMiddleware[me.APIKeyRequest], meAPI.APIKeyRevokeView)
+
+	// Two-factor authentication
+	r.POST("totp/setup", middlewares.NoImpersonationMiddleware, meAPI.TOTPSetupView)
+	r.POST("totp/enable", middlewares.NoImpersonationMiddleware, middlewares.BindJsonMiddleware[me.TOTPCodeRequest], meAPI.TOTPEnableView)
+	r.POST("totp/disable", middlewares.NoImpersonationMiddleware, middlewares.BindJsonMiddleware[me.TOTPDisableRequest], meAPI.TOTPDisableView)
+	r.POST("totp/recovery-codes", middlewares.NoImpersonationMiddleware, middlewares.BindJsonMiddleware[me.TOTPCodeRequest], meAPI.RecoveryCodesView)
+}
@@ -9,7 +9,7 @@ import (
 func ProbeRouter(g *gin.RouterGroup) {
 	probeAPI := apis.Apis.ProbeAPI
 
-	g.GET("/liveness", middlewares.LimitMiddleware(1), probeAPI.LiveView)
-	g.GET("/readiness", middlewares.LimitMiddleware(1), probeAPI.ReadyView)
+	g.GET("/liveness", middlewares.RateLimitMiddleware(), probeAPI.LiveView)
+	g.GET("/readiness", middlewares.RateLimitMiddleware(), probeAPI.ReadyView)
 
 }
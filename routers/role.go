@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"fast-gin/apis"
+	"fast-gin/apis/role"
+	"fast-gin/middlewares"
+	"fast-gin/models"
+	"fast-gin/service/svc_audit"
+	"github.com/gin-gonic/gin"
+)
+
+func RoleRouter(g *gin.RouterGroup) {
+	roleAPI := apis.Apis.RoleAPI
+
+	r := g.Group("roles").Use(
+		middlewares.AuthMiddleware,
+	)
+
+	r.GET("list", middlewares.PermissionMiddleware("roles:list"), middlewares.BindQueryMiddleware[models.PageInfo], roleAPI.ListView)
+	r.POST("", middlewares.PermissionMiddleware("roles:create"), middlewares.AuditMiddleware(svc_audit.ActionRoleCreate), middlewares.BindJsonMiddleware[role.CreateRequest], roleAPI.CreateView)
+	r.PUT(":id", middlewares.PermissionMiddleware("roles:update"), middlewares.AuditMiddleware(svc_audit.ActionRoleUpdate), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[role.UpdateRequest], roleAPI.UpdateView)
+	r.DELETE(":id", middlewares.PermissionMiddleware("roles:remove"), middlewares.AuditMiddleware(svc_audit.ActionRoleRemove), middlewares.BindUriMiddleware[models.IDRequest], roleAPI.RemoveView)
+	r.PUT(":id/permissions", middlewares.PermissionMiddleware("roles:update"), middlewares.AuditMiddleware(svc_audit.ActionRolePermissions), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[role.SetPermissionsRequest], roleAPI.SetPermissionsView)
+
+	g.GET("permissions", middlewares.AuthMiddleware, middlewares.PermissionMiddleware("roles:list"), roleAPI.PermissionListView)
+}
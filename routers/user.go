@@ -2,22 +2,60 @@ package routers
 
 import (
 	"fast-gin/apis"
+	"fast-gin/apis/me"
 	"fast-gin/apis/user"
 	"fast-gin/middlewares"
 	"fast-gin/models"
+	"fast-gin/service/svc_audit"
 	"github.com/gin-gonic/gin"
 )
 
 func UserRouter(g *gin.RouterGroup) {
 	userAPI := apis.Apis.UserAPI
 
-	r := g.Group("users").Use(
-		middlewares.LimitMiddleware(1),
-		middlewares.AdminAuthMiddleware,
+	g = g.Group("users")
+	g.Use(middlewares.RateLimitMiddleware())
+
+	// Public
+	g.POST("login", middlewares.AuditMiddleware(svc_audit.ActionLogin), middlewares.BindJsonMiddleware[user.LoginRequest], userAPI.LoginView)
+	g.POST("login/mfa", middlewares.AuditMiddleware(svc_audit.ActionLogin), middlewares.BindJsonMiddleware[user.LoginMFARequest], userAPI.LoginMFAView)
+	g.POST("refresh", userAPI.RefreshView)
+	g.POST("register", middlewares.BindJsonMiddleware[user.RegisterRequest], userAPI.RegisterView)
+	g.POST("register/resend", middlewares.BindJsonMiddleware[user.ResendVerifyEmailRequest], userAPI.ResendVerifyEmailView)
+	g.GET("verify", middlewares.BindQueryMiddleware[user.VerifyEmailRequest], userAPI.VerifyEmailView)
+	g.POST("password/forgot", middlewares.BindJsonMiddleware[user.ForgotPasswordRequest], userAPI.ForgotPasswordView)
+	g.POST("password/reset", middlewares.BindJsonMiddleware[user.ResetForgottenPasswordRequest], userAPI.ResetForgottenPasswordView)
+	g.GET("oidc/login", userAPI.OIDCLoginView)
+	g.GET("oidc/callback", middlewares.AuditMiddleware(svc_audit.ActionLogin), middlewares.BindQueryMiddleware[user.OIDCCallbackRequest], userAPI.OIDCCallbackView)
+
+	a := g.Group("").Use(
+		middlewares.AuthMiddleware,
+	)
+
+	a.POST("logout", middlewares.AuditMiddleware(svc_audit.ActionLogout), userAPI.LogoutView)
+
+	// API keys are managed with a JWT only
+	a.GET(":id/api-keys", middlewares.PermissionMiddleware("users:api_keys"), middlewares.BindUriMiddleware[models.IDRequest], userAPI.APIKeyListView)
+	a.POST(":id/api-keys", middlewares.PermissionMiddleware("users:api_keys"), middlewares.AuditMiddleware(svc_audit.ActionAPIKeyCreate), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[me.APIKeyCreateRequest], userAPI.APIKeyCreateView)
+	a.DELETE(":id/api-keys/:keyID", middlewares.PermissionMiddleware("users:api_keys"), middlewares.AuditMiddleware(svc_audit.ActionAPIKeyRevoke), middlewares.BindUriMiddleware[user.APIKeyRequest], userAPI.APIKeyRevokeView)
+
+	a.POST(":id/impersonate", middlewares.PermissionMiddleware("users:impersonate"), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[user.ImpersonateRequest], userAPI.ImpersonateView)
+
+	// Also open to API keys
+	r := g.Group("").Use(
+		middlewares.APIKeyAuthMiddleware,
 	)
 
-	r.POST("login", middlewares.BindJsonMiddleware[user.LoginRequest], userAPI.LoginView)
-	r.POST("logout", userAPI.LogoutView)
+	r.GET("list", middlewares.PermissionMiddleware("users:list"), middlewares.BindQueryMiddleware[models.PageInfo], userAPI.ListView)
 
-	r.GET("list", middlewares.BindQueryMiddleware[models.PageInfo], userAPI.ListView)
+	// Management
+	r.POST("", middlewares.PermissionMiddleware("users:create"), middlewares.AuditMiddleware(svc_audit.ActionUserCreate), middlewares.BindJsonMiddleware[user.CreateRequest], userAPI.CreateView)
+	r.GET(":id", middlewares.PermissionMiddleware("users:get"), middlewares.BindUriMiddleware[models.IDRequest], userAPI.DetailView)
+	r.PUT(":id", middlewares.PermissionMiddleware("users:update"), middlewares.AuditMiddleware(svc_audit.ActionUserUpdate), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[user.UpdateRequest], userAPI.UpdateView)
+	r.DELETE(":id", middlewares.PermissionMiddleware("users:remove"), middlewares.AuditMiddleware(svc_audit.ActionUserRemove), middlewares.BindUriMiddleware[models.IDRequest], userAPI.RemoveView)
+	r.PUT(":id/password", middlewares.PermissionMiddleware("users:reset_password"), middlewares.AuditMiddleware(svc_audit.ActionUserResetPassword), middlewares.BindUriMiddleware[models.IDRequest], middlewares.BindJsonMiddleware[user.ResetPasswordRequest], userAPI.ResetPasswordView)
+	r.POST(":id/enable", middlewares.PermissionMiddleware("users:status"), middlewares.AuditMiddleware(svc_audit.ActionUserStatus), middlewares.BindUriMiddleware[models.IDRequest], userAPI.EnableView)
+	r.POST(":id/disable", middlewares.PermissionMiddleware("users:status"), middlewares.AuditMiddleware(svc_audit.ActionUserStatus), middlewares.BindUriMiddleware[models.IDRequest], userAPI.DisableView)
+	r.POST(":id/revoke", middlewares.PermissionMiddleware("users:revoke"), middlewares.AuditMiddleware(svc_audit.ActionUserRevoke), middlewares.BindUriMiddleware[models.IDRequest], userAPI.RevokeView)
+	r.POST(":id/unlock", middlewares.PermissionMiddleware("users:unlock"), middlewares.AuditMiddleware(svc_audit.ActionUserUnlock), middlewares.BindUriMiddleware[models.IDRequest], userAPI.UnlockView)
 }
@@ -0,0 +1,116 @@
+package svc_apikey
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/models"
+	"fast-gin/service/svc_rbac"
+	"fast-gin/utils/random"
+	"fast-gin/utils/sha256"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	keyPrefix = "fgk_"
+	// Last use is written at most once per interval for each key
+	touchInterval = time.Minute
+)
+
+var (
+	ErrKeyInvalid   = errors.New("invalid API key")
+	ErrKeyExpired   = errors.New("API key has expired")
+	ErrScopeInvalid = errors.New("invalid scope")
+)
+
+// CheckScopes verifies that every scope is a declared permission granted
+// to role, an API key cannot do more than its user
+func CheckScopes(roleID int8, scopes []string) error {
+	registered := make(map[string]struct{})
+	for _, code := range svc_rbac.Registered() {
+		registered[code] = struct{}{}
+	}
+	for _, scope := range scopes {
+		if _, ok := registered[scope]; !ok {
+			return fmt.Errorf("%w: %s", ErrScopeInvalid, scope)
+		}
+		if !svc_rbac.HasPermission(roleID, scope) {
+			return fmt.Errorf("%w: %s is not granted", ErrScopeInvalid, scope)
+		}
+	}
+	return nil
+}
+
+// Create issues a key for user, the plain key is returned once and never
+// stored
+func Create(user models.UserModel, name string, scopes []string, expiresAt *time.Time) (key models.APIKeyModel, plain string, err error) {
+	err = CheckScopes(user.RoleID, scopes)
+	if err != nil {
+		return
+	}
+	token, err := random.Token(32)
+	if err != nil {
+		return
+	}
+	plain = keyPrefix + token
+
+	key = models.APIKeyModel{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    plain[:len(keyPrefix)+6],
+		Hash:      sha256.GetSHA256(plain),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	err = global.DB.Create(&key).Error
+	return
+}
+
+// Authenticate resolves a plain key to its record and active user
+func Authenticate(plain string, ip string) (key models.APIKeyModel, user models.UserModel, err error) {
+	if !strings.HasPrefix(plain, keyPrefix) {
+		return key, user, ErrKeyInvalid
+	}
+	err = global.DB.Take(&key, "hash = ?", sha256.GetSHA256(plain)).Error
+	if err != nil {
+		return key, user, ErrKeyInvalid
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(now) {
+		return key, user, ErrKeyExpired
+	}
+	err = global.DB.Take(&user, key.UserID).Error
+	if err != nil || user.Status != models.UserStatusActive {
+		return key, user, ErrKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval || key.LastUsedIP != ip {
+		err = global.DB.Model(&key).UpdateColumns(map[string]any{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+		if err != nil {
+			logrus.Errorf("Failed to update last use of API key [%d]: %v", key.ID, err)
+		}
+	}
+	return key, user, nil
+}
+
+// List returns the keys of user, newest first
+func List(userID uint) ([]models.APIKeyModel, error) {
+	list := make([]models.APIKeyModel, 0)
+	err := global.DB.Where("user_id = ?", userID).Order("id desc").Find(&list).Error
+	return list, err
+}
+
+// Revoke deletes a key of user, false if user does not own it
+func Revoke(userID uint, keyID uint) bool {
+	res := global.DB.Where("id = ? AND user_id = ?", keyID, userID).Delete(&models.APIKeyModel{})
+	if res.Error != nil {
+		logrus.Errorf("Failed to revoke API key [%d]: %v", keyID, res.Error)
+		return false
+	}
+	return res.RowsAffected == 1
+}
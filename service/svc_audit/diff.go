@@ -0,0 +1,55 @@
+package svc_audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Fields changing on every update, left out of diffs
+var ignoredFields = map[string]struct{}{
+	"updatedAt": {},
+}
+
+// Diff returns the JSON fields differing between before and after as
+// {"field": [before, after]}. Fields hidden from JSON, e.g. passwords, never
+// show up. Either side may be nil for creations and removals.
+func Diff(before, after any) string {
+	b := toMap(before)
+	a := toMap(after)
+
+	diff := make(map[string][2]any)
+	for k, v := range b {
+		if _, ok := ignoredFields[k]; ok {
+			continue
+		}
+		if w, ok := a[k]; !ok || !reflect.DeepEqual(v, w) {
+			diff[k] = [2]any{v, a[k]}
+		}
+	}
+	for k, w := range a {
+		if _, ok := ignoredFields[k]; ok {
+			continue
+		}
+		if _, ok := b[k]; !ok {
+			diff[k] = [2]any{nil, w}
+		}
+	}
+	if len(diff) == 0 {
+		return ""
+	}
+	byteData, _ := json.Marshal(diff)
+	return string(byteData)
+}
+
+func toMap(v any) map[string]any {
+	m := make(map[string]any)
+	if v == nil {
+		return m
+	}
+	byteData, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+	json.Unmarshal(byteData, &m)
+	return m
+}
@@ -0,0 +1,127 @@
+package svc_audit
+
+import (
+	"fast-gin/global"
+	"fast-gin/models"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"sync/atomic"
+)
+
+// Actions, named <target>.<verb>
+const (
+	ActionLogin                = "user.login"
+	ActionLogout               = "user.logout"
+	ActionPasswordChange       = "user.change_password"
+	ActionUserCreate           = "user.create"
+	ActionUserUpdate           = "user.update"
+	ActionUserRemove           = "user.remove"
+	ActionUserResetPassword    = "user.reset_password"
+	ActionUserStatus           = "user.status"
+	ActionUserRevoke           = "user.revoke"
+	ActionUserUnlock           = "user.unlock"
+	ActionAPIKeyCreate         = "api_key.create"
+	ActionAPIKeyRevoke         = "api_key.revoke"
+	ActionRoleCreate           = "role.create"
+	ActionRoleUpdate           = "role.update"
+	ActionRolePermissions      = "role.permissions"
+	ActionRoleRemove           = "role.remove"
+	ActionImageUpload          = "image.upload"
+	ActionImpersonationStart   = "impersonation.start"
+	ActionImpersonationRequest = "impersonation.request"
+)
+
+// CLIActor names the command line as actor
+const CLIActor = "cli"
+
+var (
+	queueOnce sync.Once
+	queueMu   sync.RWMutex // Stop closes the queue, Record sends under a read lock
+	queue     chan models.AuditLogModel
+	drained   = make(chan struct{})
+	dropped   atomic.Int64
+)
+
+// Start runs the writer draining the queue filled by Record
+func Start() {
+	queueOnce.Do(func() {
+		size := global.Config().Audit.Buffer
+		if size <= 0 {
+			size = 1024
+		}
+		queue = make(chan models.AuditLogModel, size)
+		go func(queue chan models.AuditLogModel) {
+			for entry := range queue {
+				write(entry)
+			}
+			close(drained)
+		}(queue)
+	})
+}
+
+// Stop closes the queue and waits for the writer to store the entries left
+// in it, entries recorded afterwards are written at once
+func Stop() {
+	queueMu.Lock()
+	q := queue
+	queue = nil
+	queueMu.Unlock()
+	if q == nil {
+		return
+	}
+	close(q)
+	<-drained
+	logrus.Infof("Audit log queue flushed")
+}
+
+// Record queues an entry for the writer, or writes it at once when the
+// writer is not running. Entries are dropped rather than blocking the
+// request when the queue is full. Failures are logged and never fail the
+// audited action.
+func Record(entry models.AuditLogModel) {
+	if global.DB == nil {
+		return
+	}
+	if entry.Result == "" {
+		entry.Result = models.AuditResultSuccess
+	}
+
+	queueMu.RLock()
+	q := queue
+	sent := false
+	if q != nil {
+		select {
+		case q <- entry:
+			sent = true
+		default:
+		}
+	}
+	queueMu.RUnlock()
+	if q == nil {
+		write(entry)
+		return
+	}
+	if !sent {
+		logrus.Errorf("Audit log queue is full, dropped [%s] of user [%d], %d dropped so far", entry.Action, entry.UserID, dropped.Add(1))
+	}
+}
+
+func write(entry models.AuditLogModel) {
+	err := global.DB.Create(&entry).Error
+	if err != nil {
+		logrus.Errorf("Failed to record audit log [%s]: %v", entry.Action, err)
+	}
+}
+
+// FromRequest fills in the request details of an entry
+func FromRequest(c *gin.Context, entry models.AuditLogModel) models.AuditLogModel {
+	entry.Method = c.Request.Method
+	entry.Path = c.Request.URL.Path
+	entry.IP = c.ClientIP()
+	entry.UserAgent = c.Request.UserAgent()
+	if len(entry.UserAgent) > 255 {
+		entry.UserAgent = entry.UserAgent[:255]
+	}
+	return entry
+}
@@ -0,0 +1,23 @@
+package svc_audit
+
+import (
+	"fast-gin/global"
+	"fast-gin/models"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// Purge deletes the entries older than the configured retention
+func Purge() {
+	days := global.Config().Audit.Retention
+	if days <= 0 || global.DB == nil {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	res := global.DB.Where("created_at < ?", before).Delete(&models.AuditLogModel{})
+	if res.Error != nil {
+		logrus.Errorf("Failed to purge audit logs: %v", res.Error)
+		return
+	}
+	logrus.Infof("Purged %d audit logs older than %d days", res.RowsAffected, days)
+}
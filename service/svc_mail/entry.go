@@ -0,0 +1,38 @@
+package svc_mail
+
+import (
+	"fast-gin/config"
+	"fast-gin/global"
+	"fmt"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Plain text
+}
+
+// Sender delivers mails, pick one with the mail.driver option
+type Sender interface {
+	Send(msg Message) error
+}
+
+func NewSender(cfg config.Mail) (Sender, error) {
+	switch cfg.Driver {
+	case config.SMTP:
+		return SMTPSender{cfg: cfg}, nil
+	case config.OUTBOX, "":
+		return OutboxSender{Dir: cfg.Outbox, From: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("mail driver [%s] is not supported", cfg.Driver)
+	}
+}
+
+// Send delivers msg with the configured sender
+func Send(msg Message) error {
+	sender, err := NewSender(global.Config().Mail)
+	if err != nil {
+		return err
+	}
+	return sender.Send(msg)
+}
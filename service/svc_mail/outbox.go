@@ -0,0 +1,34 @@
+package svc_mail
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OutboxSender writes every mail as an .eml file into Dir instead of
+// delivering it, for development and tests
+type OutboxSender struct {
+	Dir  string
+	From string
+}
+
+func (s OutboxSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml",
+		time.Now().Format("20060102T150405.000000000"),
+		strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To),
+	)
+	file := filepath.Join(s.Dir, name)
+	err := os.WriteFile(file, render(s.From, msg), 0600)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Mail to [%s] written to [%s]", msg.To, file)
+	return nil
+}
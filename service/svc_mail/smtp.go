@@ -0,0 +1,45 @@
+package svc_mail
+
+import (
+	"bytes"
+	"fast-gin/config"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPSender delivers mails through an SMTP relay, STARTTLS is used when
+// the server supports it
+type SMTPSender struct {
+	cfg config.Mail
+}
+
+func (s SMTPSender) Send(msg Message) error {
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid mail.from: %w", err)
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{msg.To}, render(s.cfg.From, msg))
+}
+
+// render builds an RFC 5322 message
+func render(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
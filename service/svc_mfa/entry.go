@@ -0,0 +1,110 @@
+package svc_mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fast-gin/global"
+	"fast-gin/models"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/sha256"
+	"fast-gin/utils/totp"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	RecoveryCodeCount = 10
+	pendingExpiration = 10 * time.Minute
+)
+
+var ErrNoPendingSecret = errors.New("no pending TOTP enrollment")
+
+func pendingKey(userID uint) string {
+	return fmt.Sprintf("totp_pending_%d", userID)
+}
+
+// SetPendingSecret keeps a secret being enrolled until it is confirmed
+func SetPendingSecret(userID uint, secret string) error {
+	if global.Redis == nil {
+		return svc_redis.ErrRedisUnavailable
+	}
+	return global.Redis.Set(context.Background(), pendingKey(userID), secret, pendingExpiration).Err()
+}
+
+// TakePendingSecret returns the secret being enrolled and forgets it
+func TakePendingSecret(userID uint) (string, error) {
+	if global.Redis == nil {
+		return "", svc_redis.ErrRedisUnavailable
+	}
+	secret, err := global.Redis.GetDel(context.Background(), pendingKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNoPendingSecret
+	}
+	return secret, err
+}
+
+// VerifyTOTP checks code against secret of user, every code can be used
+// only once
+func VerifyTOTP(userID uint, secret string, code string) bool {
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false
+	}
+	window := time.Duration(totp.Period*(2*totp.Skew+1)) * time.Second
+	return svc_redis.MarkUsed(fmt.Sprintf("totp_used_%d_%d", userID, step), window)
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// GenerateRecoveryCodes replaces every recovery code of user, the plain
+// codes are returned once and never stored
+func GenerateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]models.RecoveryCodeModel, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b)) // 8 chars
+		codes = append(codes, fmt.Sprintf("%s-%s", code[:4], code[4:]))
+		hashes = append(hashes, models.RecoveryCodeModel{
+			UserID: userID,
+			Hash:   sha256.GetSHA256(code),
+		})
+	}
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCodeModel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&hashes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode consumes a recovery code of user
+func UseRecoveryCode(userID uint, code string) bool {
+	now := time.Now()
+	res := global.DB.Model(&models.RecoveryCodeModel{}).
+		Where("user_id = ? AND hash = ? AND used_at IS NULL", userID, sha256.GetSHA256(normalizeRecoveryCode(code))).
+		Update("used_at", &now)
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// RemoveRecoveryCodes deletes every recovery code of user
+func RemoveRecoveryCodes(userID uint) error {
+	return global.DB.Where("user_id = ?", userID).Delete(&models.RecoveryCodeModel{}).Error
+}
@@ -0,0 +1,147 @@
+package svc_oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fast-gin/config"
+	"fast-gin/global"
+	"fast-gin/service/svc_redis"
+	"fast-gin/utils/oidc"
+	"fast-gin/utils/random"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const stateExpiration = 10 * time.Minute
+
+var (
+	ErrDisabled     = errors.New("OIDC login is disabled")
+	ErrStateInvalid = errors.New("invalid or expired OIDC state")
+)
+
+var (
+	providerMu sync.Mutex
+	provider   *oidc.Provider
+)
+
+func init() {
+	// Discover again with the new settings on next use
+	global.Subscribe(func(prev, next *config.Config) {
+		if reflect.DeepEqual(prev.OIDC, next.OIDC) {
+			return
+		}
+		providerMu.Lock()
+		defer providerMu.Unlock()
+		provider = nil
+	})
+}
+
+// Provider returns the configured provider, discovery runs on first use and
+// is retried until it succeeds
+func Provider(ctx context.Context) (*oidc.Provider, error) {
+	cfg := global.Config().OIDC
+	if !cfg.Enable {
+		return nil, ErrDisabled
+	}
+
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+	discovery, err := oidc.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	provider = &oidc.Provider{
+		Discovery:    discovery,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       scopes,
+	}
+	logrus.Infof("OIDC provider [%s] discovered", discovery.Issuer)
+	return provider, nil
+}
+
+// State is kept between the redirect to the provider and the callback
+type State struct {
+	Verifier string `json:"verifier"` // PKCE
+	Nonce    string `json:"nonce"`
+}
+
+func stateKey(state string) string {
+	return fmt.Sprintf("oidc_state_%s", state)
+}
+
+// Begin returns the URL to send the user agent to
+func Begin(ctx context.Context) (string, error) {
+	p, err := Provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	if global.Redis == nil {
+		return "", svc_redis.ErrRedisUnavailable
+	}
+
+	var s State
+	state, err := random.Token(16)
+	if err != nil {
+		return "", err
+	}
+	s.Nonce, err = random.Token(16)
+	if err != nil {
+		return "", err
+	}
+	s.Verifier, err = oidc.NewVerifier()
+	if err != nil {
+		return "", err
+	}
+	byteData, _ := json.Marshal(s)
+	err = global.Redis.Set(ctx, stateKey(state), byteData, stateExpiration).Err()
+	if err != nil {
+		return "", err
+	}
+	return p.AuthCodeURL(state, s.Nonce, oidc.Challenge(s.Verifier)), nil
+}
+
+// Finish consumes state, exchanges code and returns the verified ID token
+// claims
+func Finish(ctx context.Context, state, code string) (*oidc.IDTokenClaims, error) {
+	p, err := Provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if global.Redis == nil {
+		return nil, svc_redis.ErrRedisUnavailable
+	}
+
+	// A state can be used only once
+	byteData, err := global.Redis.GetDel(ctx, stateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	err = json.Unmarshal(byteData, &s)
+	if err != nil {
+		return nil, ErrStateInvalid
+	}
+
+	res, err := p.Exchange(ctx, code, s.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.Verify(ctx, res.IDToken, s.Nonce)
+}
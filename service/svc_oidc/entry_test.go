@@ -0,0 +1,131 @@
+package svc_oidc
+
+import (
+	"context"
+	"errors"
+	"fast-gin/config"
+	"fast-gin/global"
+	"fast-gin/utils/oidc/oidctest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// setup points the service at a mock provider and an in-memory Redis
+func setup(t *testing.T) *oidctest.Server {
+	idp := oidctest.NewServer("fast-gin", "secret")
+	t.Cleanup(idp.Close)
+	mr := miniredis.RunT(t)
+	global.Redis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	cfg := config.Default()
+	cfg.OIDC = config.OIDC{
+		Enable:       true,
+		Issuer:       idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://app.test/v1/users/oidc/callback",
+	}
+	global.SetConfig(cfg)
+	providerMu.Lock()
+	provider = nil
+	providerMu.Unlock()
+	return idp
+}
+
+func TestBeginFinish(t *testing.T) {
+	idp := setup(t)
+	ctx := context.Background()
+
+	authURL, err := Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	if q := u.Query(); q.Get("code_challenge_method") != "S256" || q.Get("nonce") == "" || q.Get("state") == "" {
+		t.Fatalf("authorization URL lacks PKCE, nonce or state: %s", authURL)
+	}
+
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	claims, err := Finish(ctx, state, code)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if claims.Subject != idp.User.Subject || claims.Issuer != idp.URL {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestFinishStateMismatch(t *testing.T) {
+	idp := setup(t)
+	ctx := context.Background()
+
+	authURL, _ := Begin(ctx)
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	_, err = Finish(ctx, state+"x", code)
+	if !errors.Is(err, ErrStateInvalid) {
+		t.Fatalf("Finish with another state: %v, want ErrStateInvalid", err)
+	}
+	_, err = Finish(ctx, state, code)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	// A state is consumed by the callback
+	_, err = Finish(ctx, state, code)
+	if !errors.Is(err, ErrStateInvalid) {
+		t.Fatalf("Finish with a used state: %v, want ErrStateInvalid", err)
+	}
+}
+
+func TestFinishNonceMismatch(t *testing.T) {
+	idp := setup(t)
+	ctx := context.Background()
+
+	// The provider answers with an ID token issued for another request
+	idp.Claims = func(c jwt.MapClaims) { c["nonce"] = "replayed" }
+	authURL, _ := Begin(ctx)
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	_, err = Finish(ctx, state, code)
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("Finish: %v, want nonce mismatch", err)
+	}
+}
+
+func TestFinishKeyRotation(t *testing.T) {
+	idp := setup(t)
+	ctx := context.Background()
+
+	login := func() error {
+		authURL, err := Begin(ctx)
+		if err != nil {
+			return err
+		}
+		code, state, err := idp.Authorize(authURL)
+		if err != nil {
+			return err
+		}
+		_, err = Finish(ctx, state, code)
+		return err
+	}
+	if err := login(); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	idp.Rotate(false)
+	if err := login(); err != nil {
+		t.Fatalf("login after key rotation: %v", err)
+	}
+}
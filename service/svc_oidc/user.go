@@ -0,0 +1,120 @@
+package svc_oidc
+
+import (
+	"errors"
+	"fast-gin/global"
+	"fast-gin/models"
+	"fast-gin/utils/oidc"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+)
+
+var ErrNotLinked = errors.New("no user is linked to this account")
+
+var usernameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// ResolveUser returns the user linked to the account of claims, linking by
+// verified email or provisioning a new user when configured
+func ResolveUser(claims *oidc.IDTokenClaims) (user models.UserModel, err error) {
+	cfg := global.Config().OIDC
+	email := strings.ToLower(claims.Email)
+
+	var identity models.UserIdentityModel
+	err = global.DB.Take(&identity, "issuer = ? AND subject = ?", claims.Issuer, claims.Subject).Error
+	if err == nil {
+		err = global.DB.Take(&user, identity.UserID).Error
+		if err == nil && email != "" && identity.Email != email {
+			global.DB.Model(&identity).Update("email", email)
+		}
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	switch {
+	case cfg.LinkByEmail && claims.EmailVerified && email != "" &&
+		global.DB.Take(&user, "email = ?", email).Error == nil:
+		logrus.Infof("Linking OIDC account [%s] to user [%s] by email", claims.Subject, user.Username)
+	case cfg.AutoProvision:
+		user, err = provision(claims, email)
+		if err != nil {
+			return
+		}
+		logrus.Infof("Provisioned user [%s] for OIDC account [%s]", user.Username, claims.Subject)
+	default:
+		return user, ErrNotLinked
+	}
+
+	err = global.DB.Create(&models.UserIdentityModel{
+		UserID:  user.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   email,
+	}).Error
+	return
+}
+
+// provision creates a user without a local password
+func provision(claims *oidc.IDTokenClaims, email string) (user models.UserModel, err error) {
+	roleID := global.Config().OIDC.RoleID
+	if roleID == 0 {
+		roleID = models.UserRoleID
+	}
+	if !claims.EmailVerified {
+		email = ""
+	}
+	if email != "" && global.DB.Take(&models.UserModel{}, "email = ?", email).Error == nil {
+		// Taken by a user who was not linked, do not share it
+		email = ""
+	}
+
+	user = models.UserModel{
+		Username: uniqueUsername(claims, email),
+		Nickname: claims.Name,
+		Email:    email,
+		RoleID:   roleID,
+		Status:   models.UserStatusActive,
+	}
+	if len([]rune(user.Nickname)) > 32 {
+		user.Nickname = string([]rune(user.Nickname)[:32])
+	}
+	err = global.DB.Create(&user).Error
+	return
+}
+
+// uniqueUsername derives a free username of at most 16 characters from the
+// claims
+func uniqueUsername(claims *oidc.IDTokenClaims, email string) string {
+	base := claims.PreferredUsername
+	if base == "" && email != "" {
+		base = strings.SplitN(email, "@", 2)[0]
+	}
+	base = usernameInvalid.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 12 {
+		base = base[:12]
+	}
+
+	username := base
+	for i := 1; ; i++ {
+		var count int64
+		global.DB.Model(&models.UserModel{}).Where("username = ?", username).Count(&count)
+		if count == 0 {
+			return username
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+}
+
+// IsLinked reports whether user logs in through the identity provider
+func IsLinked(userID uint) bool {
+	var count int64
+	global.DB.Model(&models.UserIdentityModel{}).Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
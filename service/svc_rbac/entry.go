@@ -0,0 +1,120 @@
+package svc_rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fast-gin/global"
+	"fast-gin/models"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"time"
+)
+
+const cacheExpiration = 10 * time.Minute
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]struct{})
+)
+
+// Register records a permission declared by a route
+func Register(code string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[code] = struct{}{}
+}
+
+// Registered returns every permission declared by routes
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	codes := make([]string, 0, len(registry))
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// SyncPermissions creates the permissions declared by routes which are
+// missing in database
+func SyncPermissions() {
+	if global.DB == nil {
+		return
+	}
+	for _, code := range Registered() {
+		var perm models.PermissionModel
+		err := global.DB.Where(models.PermissionModel{Code: code}).FirstOrCreate(&perm).Error
+		if err != nil {
+			logrus.Errorf("Failed to sync permission [%s]: %s", code, err)
+		}
+	}
+	logrus.Infof("Permissions synced successfully")
+}
+
+func cacheKey(roleID int8) string {
+	return fmt.Sprintf("role_permissions_%d", roleID)
+}
+
+// GetPermissions returns the permission codes granted to role, cached in Redis
+func GetPermissions(roleID int8) ([]string, error) {
+	ctx := context.Background()
+	if global.Redis != nil {
+		byteData, err := global.Redis.Get(ctx, cacheKey(roleID)).Bytes()
+		if err == nil {
+			var codes []string
+			if json.Unmarshal(byteData, &codes) == nil {
+				return codes, nil
+			}
+		}
+	}
+
+	codes := make([]string, 0)
+	err := global.DB.Model(&models.PermissionModel{}).
+		Joins("JOIN role_permission_models ON role_permission_models.permission_id = permission_models.id").
+		Where("role_permission_models.role_id = ?", roleID).
+		Pluck("permission_models.code", &codes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if global.Redis != nil {
+		byteData, _ := json.Marshal(codes)
+		err = global.Redis.Set(ctx, cacheKey(roleID), byteData, cacheExpiration).Err()
+		if err != nil {
+			logrus.Errorf("Failed to cache permissions of role [%d]: %v", roleID, err)
+		}
+	}
+	return codes, nil
+}
+
+// HasPermission reports whether role is granted code, admin is granted all
+func HasPermission(roleID int8, code string) bool {
+	if roleID == models.AdminRoleID {
+		return true
+	}
+	codes, err := GetPermissions(roleID)
+	if err != nil {
+		logrus.Errorf("Failed to get permissions of role [%d]: %v", roleID, err)
+		return false
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateRole drops the cached permissions of role
+func InvalidateRole(roleID int8) {
+	if global.Redis == nil {
+		return
+	}
+	err := global.Redis.Del(context.Background(), cacheKey(roleID)).Err()
+	if err != nil {
+		logrus.Errorf("Failed to invalidate permissions of role [%d]: %v", roleID, err)
+	}
+}
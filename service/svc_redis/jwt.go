@@ -10,6 +10,9 @@ import (
 )
 
 func Logout(token string) {
+	if global.Redis == nil {
+		return
+	}
 	claims, err := jwts.ValidateJWT(token)
 	if err != nil {
 		logrus.Errorf("Failed to validate JWT: %v", err)
@@ -26,6 +29,9 @@ func Logout(token string) {
 }
 
 func HasLoggedOut(token string) bool {
+	if global.Redis == nil {
+		return false
+	}
 	key := fmt.Sprintf("logout_%s", token)
 	_, err := global.Redis.Get(context.Background(), key).Result()
 	if err == nil {
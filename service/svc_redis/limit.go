@@ -0,0 +1,32 @@
+package svc_redis
+
+import (
+	"context"
+	"fast-gin/global"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Allow counts one hit on key within a fixed window and reports whether the
+// number of hits is still within limit. Always allows when Redis is down.
+func Allow(key string, limit int64, window time.Duration) (bool, error) {
+	if global.Redis == nil {
+		return true, nil
+	}
+	ctx := context.Background()
+	var incr *redis.IntCmd
+	var ttl *redis.DurationCmd
+	_, err := global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		ttl = pipe.TTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return true, err
+	}
+	// First hit of the window, or the expiration got lost
+	if ttl.Val() < 0 {
+		global.Redis.Expire(ctx, key, window)
+	}
+	return incr.Val() <= limit, nil
+}
@@ -0,0 +1,150 @@
+package svc_redis
+
+import (
+	"context"
+	"fast-gin/global"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// Lockout history of a username is kept this long, further lockouts within
+// it last twice as long as the previous one
+const lockoutHistory = 24 * time.Hour
+
+func loginFailKey(kind, id string) string {
+	return fmt.Sprintf("login_fail_%s_%s", kind, id)
+}
+
+func loginLockKey(kind, id string) string {
+	return fmt.Sprintf("login_lock_%s_%s", kind, id)
+}
+
+func loginLockoutsKey(kind, id string) string {
+	return fmt.Sprintf("login_lockouts_%s_%s", kind, id)
+}
+
+// LoginFailures returns the recent failed logins of username or ip,
+// whichever is higher
+func LoginFailures(username, ip string) int64 {
+	if global.Redis == nil {
+		return 0
+	}
+	values, err := global.Redis.MGet(context.Background(),
+		loginFailKey("user", username), loginFailKey("ip", ip)).Result()
+	if err != nil {
+		logrus.Errorf("Failed to get login failures from Redis: %v", err)
+		return 0
+	}
+	var max int64
+	for _, v := range values {
+		var n int64
+		if s, ok := v.(string); ok {
+			fmt.Sscan(s, &n)
+		}
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// LoginLocked returns how long logins of username or ip are still locked
+func LoginLocked(username, ip string) (time.Duration, bool) {
+	if global.Redis == nil {
+		return 0, false
+	}
+	ctx := context.Background()
+	var remaining time.Duration
+	for _, key := range []string{loginLockKey("user", username), loginLockKey("ip", ip)} {
+		ttl, err := global.Redis.TTL(ctx, key).Result()
+		if err != nil {
+			logrus.Errorf("Failed to check login lock in Redis: %v", err)
+			continue
+		}
+		if ttl > remaining {
+			remaining = ttl
+		}
+	}
+	return remaining, remaining > 0
+}
+
+// RecordLoginFailure counts a failed login of username from ip, and locks
+// either of them once it reaches its limit
+func RecordLoginFailure(username, ip string) {
+	if global.Redis == nil {
+		return
+	}
+	cfg := global.Config().Site.Login
+	window := time.Duration(cfg.FailureWindow) * time.Minute
+	if window <= 0 {
+		window = 15 * time.Minute
+	}
+	recordFailure("user", username, int64(cfg.MaxFailures), window)
+	recordFailure("ip", ip, int64(cfg.IPMaxFailures), window)
+}
+
+func recordFailure(kind, id string, limit int64, window time.Duration) {
+	ok, err := Allow(loginFailKey(kind, id), limit-1, window)
+	if err != nil {
+		logrus.Errorf("Failed to record login failure in Redis: %v", err)
+		return
+	}
+	if limit <= 0 || ok {
+		return
+	}
+
+	// Exponential backoff: lockout, 2*lockout, 4*lockout... up to max
+	ctx := context.Background()
+	lockouts, err := global.Redis.Incr(ctx, loginLockoutsKey(kind, id)).Result()
+	if err != nil {
+		logrus.Errorf("Failed to record lockout in Redis: %v", err)
+		return
+	}
+	global.Redis.Expire(ctx, loginLockoutsKey(kind, id), lockoutHistory)
+	cfg := global.Config().Site.Login
+	duration := time.Duration(cfg.Lockout) * time.Minute
+	maxDuration := time.Duration(cfg.MaxLockout) * time.Minute
+	for i := int64(1); i < lockouts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	if maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+	if duration <= 0 {
+		return
+	}
+
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, loginLockKey(kind, id), "", duration)
+		pipe.Del(ctx, loginFailKey(kind, id))
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to lock login in Redis: %v", err)
+		return
+	}
+	logrus.Warnf("Login of %s [%s] locked for %s after repeated failures", kind, id, duration)
+}
+
+// ResetLoginFailures forgets the failed logins of username after a
+// successful one, failures of the IP keep counting
+func ResetLoginFailures(username string) {
+	if global.Redis == nil {
+		return
+	}
+	global.Redis.Del(context.Background(), loginFailKey("user", username))
+}
+
+// UnlockLogin lifts the lockout of username and clears its history
+func UnlockLogin(username string) error {
+	if global.Redis == nil {
+		return ErrRedisUnavailable
+	}
+	return global.Redis.Del(context.Background(),
+		loginFailKey("user", username),
+		loginLockKey("user", username),
+		loginLockoutsKey("user", username),
+	).Err()
+}
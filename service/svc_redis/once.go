@@ -0,0 +1,35 @@
+package svc_redis
+
+import (
+	"context"
+	"fast-gin/global"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// MarkUsed records key as used for ttl and reports whether it was the first
+// use, callers reject the second one. Always first when Redis is down.
+func MarkUsed(key string, ttl time.Duration) bool {
+	if global.Redis == nil {
+		return true
+	}
+	ok, err := global.Redis.SetNX(context.Background(), key, "", ttl).Result()
+	if err != nil {
+		logrus.Errorf("Failed to mark key as used in Redis: %v", err)
+		return true
+	}
+	return ok
+}
+
+// IsUsed reports whether key has been marked by MarkUsed
+func IsUsed(key string) bool {
+	if global.Redis == nil {
+		return false
+	}
+	n, err := global.Redis.Exists(context.Background(), key).Result()
+	if err != nil {
+		logrus.Errorf("Failed to check key in Redis: %v", err)
+		return false
+	}
+	return n > 0
+}
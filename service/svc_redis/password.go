@@ -0,0 +1,75 @@
+package svc_redis
+
+import (
+	"context"
+	"errors"
+	"fast-gin/global"
+	"fast-gin/utils/random"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+var ErrResetTokenInvalid = errors.New("invalid password reset token")
+
+func resetTokenKey(hash string) string {
+	return fmt.Sprintf("pwd_reset_%s", hash)
+}
+
+func resetUserKey(userID uint) string {
+	return fmt.Sprintf("pwd_reset_user_%d", userID)
+}
+
+// IssuePasswordResetToken creates a single-use reset token for user, only its
+// hash is stored. Any previous token of user is invalidated.
+func IssuePasswordResetToken(userID uint, expiration time.Duration) (string, error) {
+	if global.Redis == nil {
+		return "", ErrRedisUnavailable
+	}
+	token, err := random.Token(32)
+	if err != nil {
+		return "", err
+	}
+	hash := hashToken(token)
+
+	ctx := context.Background()
+	old, err := global.Redis.Get(ctx, resetUserKey(userID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if old != "" {
+			pipe.Del(ctx, resetTokenKey(old))
+		}
+		pipe.Set(ctx, resetTokenKey(hash), userID, expiration)
+		pipe.Set(ctx, resetUserKey(userID), hash, expiration)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumePasswordResetToken returns the user a reset token was issued for and
+// deletes it, so that it cannot be used twice
+func ConsumePasswordResetToken(token string) (uint, error) {
+	if global.Redis == nil {
+		return 0, ErrRedisUnavailable
+	}
+	ctx := context.Background()
+	value, err := global.Redis.GetDel(ctx, resetTokenKey(hashToken(token))).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrResetTokenInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, ErrResetTokenInvalid
+	}
+	global.Redis.Del(ctx, resetUserKey(uint(userID)))
+	return uint(userID), nil
+}
@@ -0,0 +1,175 @@
+package svc_redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fast-gin/global"
+	"fast-gin/utils/random"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+var (
+	ErrRedisUnavailable    = errors.New("redis is not available")
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// RefreshSession is stored for each refresh token, all tokens rotated from
+// the same login share one family, which is also the session of the user
+type RefreshSession struct {
+	Family     string    `json:"family"`
+	UserID     uint      `json:"userID"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshTokenKey(token string) string {
+	return fmt.Sprintf("refresh_token_%s", hashToken(token))
+}
+
+func refreshUsedKey(token string) string {
+	return fmt.Sprintf("refresh_used_%s", hashToken(token))
+}
+
+func refreshFamilyKey(family string) string {
+	return fmt.Sprintf("refresh_family_%s", family)
+}
+
+func refreshUserKey(userID uint) string {
+	return fmt.Sprintf("refresh_user_%d", userID)
+}
+
+func refreshExpiration() time.Duration {
+	return time.Duration(global.Config().JWT.RefreshExpire) * time.Hour
+}
+
+// IssueRefreshToken starts a new token family, Family and timestamps of
+// session are filled in
+func IssueRefreshToken(session RefreshSession) (RefreshSession, string, error) {
+	if global.Redis == nil {
+		return session, "", ErrRedisUnavailable
+	}
+	family, err := random.Token(16)
+	if err != nil {
+		return session, "", err
+	}
+	session.Family = family
+	session.CreatedAt = time.Now()
+	session.LastUsedAt = session.CreatedAt
+
+	token, err := issueInFamily(session)
+	return session, token, err
+}
+
+func issueInFamily(session RefreshSession) (string, error) {
+	token, err := random.Token(32)
+	if err != nil {
+		return "", err
+	}
+	byteData, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	expiration := refreshExpiration()
+	_, err = global.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, refreshTokenKey(token), byteData, expiration)
+		pipe.Set(ctx, refreshFamilyKey(session.Family), byteData, expiration)
+		pipe.SAdd(ctx, refreshUserKey(session.UserID), session.Family)
+		pipe.Expire(ctx, refreshUserKey(session.UserID), expiration)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to store refresh token in Redis: %v", err)
+		return "", err
+	}
+	return token, nil
+}
+
+func getSession(token string) (session RefreshSession, err error) {
+	ctx := context.Background()
+	byteData, err := global.Redis.Get(ctx, refreshTokenKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return session, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return session, err
+	}
+	err = json.Unmarshal(byteData, &session)
+	return
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same
+// family. Presenting an already rotated token revokes the whole family.
+func RotateRefreshToken(token, ip, userAgent string) (session RefreshSession, newToken string, err error) {
+	if global.Redis == nil {
+		return session, "", ErrRedisUnavailable
+	}
+	session, err = getSession(token)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	n, err := global.Redis.Exists(ctx, refreshFamilyKey(session.Family)).Result()
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		return session, "", ErrRefreshTokenInvalid
+	}
+
+	// Mark token as used, only the first caller wins
+	ok, err := global.Redis.SetNX(ctx, refreshUsedKey(token), "", refreshExpiration()).Result()
+	if err != nil {
+		return
+	}
+	if !ok {
+		logrus.Warnf("Refresh token reuse detected, revoking family of user [%d]", session.UserID)
+		RevokeRefreshFamily(session.Family)
+		return session, "", ErrRefreshTokenReused
+	}
+
+	session.IP = ip
+	session.UserAgent = userAgent
+	session.LastUsedAt = time.Now()
+	newToken, err = issueInFamily(session)
+	return
+}
+
+// RevokeRefreshFamily invalidates every refresh token of a family
+func RevokeRefreshFamily(family string) {
+	if global.Redis == nil {
+		return
+	}
+	err := global.Redis.Del(context.Background(), refreshFamilyKey(family)).Err()
+	if err != nil {
+		logrus.Errorf("Failed to revoke refresh token family: %v", err)
+	}
+}
+
+// RevokeRefreshToken invalidates the family the given refresh token belongs to
+func RevokeRefreshToken(token string) {
+	if global.Redis == nil {
+		return
+	}
+	session, err := getSession(token)
+	if err != nil {
+		return
+	}
+	RevokeRefreshFamily(session.Family)
+}
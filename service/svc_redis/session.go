@@ -0,0 +1,94 @@
+package svc_redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fast-gin/global"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"sort"
+)
+
+// SessionActive reports whether the session an access token was issued for
+// has not been revoked
+func SessionActive(sessionID string) bool {
+	if global.Redis == nil || sessionID == "" {
+		return true
+	}
+	n, err := global.Redis.Exists(context.Background(), refreshFamilyKey(sessionID)).Result()
+	if err != nil {
+		logrus.Errorf("Failed to check session in Redis: %v", err)
+		return true
+	}
+	return n > 0
+}
+
+func getFamily(family string) (session RefreshSession, err error) {
+	byteData, err := global.Redis.Get(context.Background(), refreshFamilyKey(family)).Bytes()
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(byteData, &session)
+	return
+}
+
+// ListSessions returns the active sessions of user, most recently used first
+func ListSessions(userID uint) ([]RefreshSession, error) {
+	list := make([]RefreshSession, 0)
+	if global.Redis == nil {
+		return list, nil
+	}
+
+	ctx := context.Background()
+	families, err := global.Redis.SMembers(ctx, refreshUserKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, family := range families {
+		session, err := getFamily(family)
+		if errors.Is(err, redis.Nil) {
+			// Expired or revoked
+			global.Redis.SRem(ctx, refreshUserKey(userID), family)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, session)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastUsedAt.After(list[j].LastUsedAt)
+	})
+	return list, nil
+}
+
+// RevokeSession revokes a session of user, false if user does not own it
+func RevokeSession(userID uint, sessionID string) bool {
+	if global.Redis == nil {
+		return false
+	}
+	session, err := getFamily(sessionID)
+	if err != nil || session.UserID != userID {
+		return false
+	}
+	RevokeRefreshFamily(sessionID)
+	global.Redis.SRem(context.Background(), refreshUserKey(userID), sessionID)
+	return true
+}
+
+// RevokeUserSessions revokes every session of user except the given one,
+// pass an empty except to revoke all
+func RevokeUserSessions(userID uint, except string) {
+	list, err := ListSessions(userID)
+	if err != nil {
+		logrus.Errorf("Failed to list sessions of user [%d]: %v", userID, err)
+		return
+	}
+	for _, session := range list {
+		if session.Family == except {
+			continue
+		}
+		RevokeSession(userID, session.Family)
+	}
+}
@@ -0,0 +1,19 @@
+package svc_user
+
+import (
+	"fast-gin/global"
+	"fast-gin/models"
+)
+
+// IsLastAdmin reports whether user is the only active admin left, removing,
+// disabling or demoting such a user would lock everyone out
+func IsLastAdmin(user models.UserModel) bool {
+	if user.RoleID != models.AdminRoleID || user.Status != models.UserStatusActive {
+		return false
+	}
+	var count int64
+	global.DB.Model(&models.UserModel{}).
+		Where("role_id = ? AND status = ? AND id <> ?", models.AdminRoleID, models.UserStatusActive, user.ID).
+		Count(&count)
+	return count == 0
+}
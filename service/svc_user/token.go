@@ -0,0 +1,97 @@
+package svc_user
+
+import (
+	"context"
+	"fast-gin/global"
+	"fast-gin/models"
+	"fast-gin/service/svc_redis"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+const tokenVersionExpiration = time.Hour
+
+func tokenVersionKey(userID uint) string {
+	return fmt.Sprintf("token_version_%d", userID)
+}
+
+// TokenVersion returns the current token version of user, tokens carrying
+// another version have been revoked. Cached in Redis.
+func TokenVersion(userID uint) (uint, error) {
+	ctx := context.Background()
+	if global.Redis != nil {
+		value, err := global.Redis.Get(ctx, tokenVersionKey(userID)).Result()
+		if err == nil {
+			version, err := strconv.ParseUint(value, 10, 64)
+			if err == nil {
+				return uint(version), nil
+			}
+		}
+	}
+
+	var user models.UserModel
+	err := global.DB.Select("token_version").Take(&user, userID).Error
+	if err != nil {
+		return 0, err
+	}
+	cacheTokenVersion(userID, user.TokenVersion)
+	return user.TokenVersion, nil
+}
+
+// cacheTokenVersion caches a version read from the database unless one is
+// cached already, which may be newer than what this request read
+func cacheTokenVersion(userID uint, version uint) {
+	if global.Redis == nil {
+		return
+	}
+	err := global.Redis.SetNX(context.Background(), tokenVersionKey(userID), version, tokenVersionExpiration).Err()
+	if err != nil {
+		logrus.Errorf("Failed to cache token version of user [%d]: %v", userID, err)
+	}
+}
+
+// raiseTokenVersion caches the version in ARGV[1] unless a higher one is
+// cached, versions only grow so the cache never goes back to a revoked one
+var raiseTokenVersion = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]))
+if current and current >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// RevokeTokens invalidates every access and refresh token user holds
+func RevokeTokens(userID uint) error {
+	var user models.UserModel
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserModel{}).Where("id = ?", userID).
+			Update("token_version", gorm.Expr("token_version + 1")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Select("token_version").Take(&user, userID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write the new version rather than dropping the key, a request which
+	// read the old version before the update would cache it again
+	if global.Redis != nil {
+		ctx := context.Background()
+		key := tokenVersionKey(userID)
+		err = raiseTokenVersion.Run(ctx, global.Redis, []string{key}, user.TokenVersion, tokenVersionExpiration.Milliseconds()).Err()
+		if err != nil {
+			logrus.Errorf("Failed to cache token version of user [%d]: %v", userID, err)
+			global.Redis.Del(ctx, key)
+		}
+	}
+	svc_redis.RevokeUserSessions(userID, "")
+	logrus.Infof("Tokens of user [%d] revoked", userID)
+	return nil
+}
@@ -10,9 +10,24 @@ import (
 
 // CustomClaims defines the structure of the token's payload
 
+// Actor is the admin acting on behalf of the user of an impersonation
+// token (RFC 8693 act claim)
+type Actor struct {
+	UserID   uint   `json:"userID"`
+	Username string `json:"username"`
+	Version  uint   `json:"ver"` // UserModel.TokenVersion of the admin at issuance
+}
+
 type ClaimMeta struct {
-	UserID uint `json:"userID"`
-	RoleID int8 `json:"roleID"`
+	UserID    uint   `json:"userID"`
+	RoleID    int8   `json:"roleID"`
+	SessionID string `json:"sid,omitempty"` // Refresh token family the token was issued for
+	Version   uint   `json:"ver"`           // UserModel.TokenVersion at issuance
+	Actor     *Actor `json:"act,omitempty"` // Set on impersonation tokens
+
+	// Set when authenticated by an API key instead of a JWT, never signed
+	APIKeyID uint     `json:"-"`
+	Scopes   []string `json:"-"`
 }
 
 type CustomClaims struct {
@@ -26,17 +41,48 @@ func GenerateJWT(meta ClaimMeta) (string, error) {
 	claims := CustomClaims{
 		ClaimMeta: meta,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(global.Config.JWT.Expire) * time.Hour)), // Expires in 24 hours
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(global.Config().JWT.Expire) * time.Hour)), // Expires in JWT.Expire hours
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    global.Config.JWT.Issuer,
+			Issuer:    global.Config().JWT.Issuer,
 		},
 	}
 
-	// Create token with HS256 signing method
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return sign(claims)
+}
 
-	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(global.Config.JWT.SecretKey))
+// GenerateImpersonationJWT creates a short-lived token for the user of meta,
+// meta.Actor names the admin. No refresh token goes with it.
+func GenerateImpersonationJWT(meta ClaimMeta, expire time.Duration) (string, error) {
+	if meta.Actor == nil {
+		return "", fmt.Errorf("impersonation token without actor")
+	}
+	claims := CustomClaims{
+		ClaimMeta: meta,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    global.Config().JWT.Issuer,
+		},
+	}
+
+	return sign(claims)
+}
+
+// sign signs claims with the current signing key
+func sign(claims jwt.Claims) (string, error) {
+	set, err := currentKeys()
+	if err != nil {
+		return "", err
+	}
+
+	// Create token with the configured signing method
+	token := jwt.NewWithClaims(set.signing.method, claims)
+	if set.signing.kid != "" {
+		token.Header["kid"] = set.signing.kid
+	}
+
+	// Sign the token with the signing key
+	tokenString, err := token.SignedString(set.signing.private)
 	if err != nil {
 		logrus.Errorf("Error signing jwt: %v", err)
 		return "", err
@@ -48,24 +94,40 @@ func GenerateJWT(meta ClaimMeta) (string, error) {
 // ValidateJWT parses and validates a JWT token
 func ValidateJWT(tokenString string) (*CustomClaims, error) {
 	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(global.Config.JWT.SecretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, keyFunc)
 
 	if err != nil {
 		logrus.Errorf("Error parsing jwt: %v", err)
 		return nil, err
 	}
 
-	// Extract claims if token is valid
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
+	// Extract claims if token is valid, action tokens carry an audience and
+	// are never accepted as access tokens
+	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid && len(claims.Audience) == 0 {
 		return claims, nil
 	}
 
 	logrus.Errorf("Error parsing jwt: %v", err)
 	return nil, fmt.Errorf("invalid token")
 }
+
+// keyFunc selects the verification key by kid and rejects any algorithm
+// other than the one of that key
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	set, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	k, ok := set.verify[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid: %s", kid)
+	}
+
+	// Verify signing method
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return k.public, nil
+}
@@ -0,0 +1,201 @@
+package jwts
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fast-gin/config"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"sync"
+)
+
+type key struct {
+	kid     string
+	method  jwt.SigningMethod
+	private any // Signing key, nil for verification only keys
+	public  any // Verification key
+}
+
+type keySet struct {
+	signing *key
+	verify  map[string]*key
+}
+
+var (
+	keysMu sync.RWMutex
+	keys   *keySet
+)
+
+func currentKeys() (*keySet, error) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if keys == nil {
+		return nil, errors.New("jwt keys are not loaded")
+	}
+	return keys, nil
+}
+
+// LoadKeys builds the signing and verification keys from configuration,
+// it can be called again to rotate keys at runtime
+func LoadKeys(cfg config.JWT) error {
+	apply, err := PrepareKeys(cfg)
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// PrepareKeys builds the keys of cfg, apply makes them current. The current
+// keys are kept when cfg is rejected.
+func PrepareKeys(cfg config.JWT) (apply func(), err error) {
+	var set *keySet
+	switch cfg.Algorithm {
+	case "", "HS256":
+		set, err = loadHMACKeys(cfg)
+	case "RS256", "ES256", "EdDSA":
+		set, err = loadAsymmetricKeys(cfg)
+	default:
+		err = fmt.Errorf("unsupported jwt algorithm: %s", cfg.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		keysMu.Lock()
+		keys = set
+		keysMu.Unlock()
+	}, nil
+}
+
+func loadHMACKeys(cfg config.JWT) (*keySet, error) {
+	if cfg.SecretKey == "" {
+		return nil, errors.New("jwt secret_key is required for HS256")
+	}
+	k := &key{
+		method:  jwt.SigningMethodHS256,
+		private: []byte(cfg.SecretKey),
+		public:  []byte(cfg.SecretKey),
+	}
+	return &keySet{
+		signing: k,
+		verify:  map[string]*key{"": k},
+	}, nil
+}
+
+func loadAsymmetricKeys(cfg config.JWT) (*keySet, error) {
+	set := &keySet{verify: make(map[string]*key)}
+	for _, kc := range cfg.Keys {
+		if kc.KID == "" {
+			return nil, errors.New("jwt key without kid")
+		}
+		if _, ok := set.verify[kc.KID]; ok {
+			return nil, fmt.Errorf("duplicated jwt kid: %s", kc.KID)
+		}
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key [%s]: %w", kc.KID, err)
+		}
+		set.verify[kc.KID] = k
+	}
+
+	signing, ok := set.verify[cfg.SigningKID]
+	if !ok {
+		return nil, fmt.Errorf("signing kid [%s] not found in jwt keys", cfg.SigningKID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("signing key [%s] has no private key", cfg.SigningKID)
+	}
+	if signing.method.Alg() != cfg.Algorithm {
+		return nil, fmt.Errorf("signing key [%s] is %s, but algorithm is %s", cfg.SigningKID, signing.method.Alg(), cfg.Algorithm)
+	}
+	set.signing = signing
+	return set, nil
+}
+
+func loadKey(kc config.JWTKey) (*key, error) {
+	k := &key{kid: kc.KID}
+
+	if kc.PrivateKey != "" {
+		pemData, err := os.ReadFile(kc.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		k.private, err = parsePrivateKey(pemData)
+		if err != nil {
+			return nil, err
+		}
+		k.public = k.private.(crypto.Signer).Public()
+	}
+
+	if kc.PublicKey != "" {
+		pemData, err := os.ReadFile(kc.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		k.public, err = parsePublicKey(pemData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if k.public == nil {
+		return nil, errors.New("private_key or public_key is required")
+	}
+
+	var err error
+	k.method, err = methodOf(k.public)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func parsePrivateKey(pemData []byte) (any, error) {
+	if k, err := jwt.ParseRSAPrivateKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	if k, err := jwt.ParseECPrivateKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	if k, err := jwt.ParseEdPrivateKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("unsupported private key, expecting RSA, ECDSA or Ed25519 PEM")
+}
+
+func parsePublicKey(pemData []byte) (any, error) {
+	if k, err := jwt.ParseRSAPublicKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	if k, err := jwt.ParseECPublicKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	if k, err := jwt.ParseEdPublicKeyFromPEM(pemData); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("unsupported public key, expecting RSA, ECDSA or Ed25519 PEM")
+}
+
+// methodOf infers the signing method from the type of public key
+func methodOf(public any) (jwt.SigningMethod, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curve is supported for ECDSA keys")
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
@@ -0,0 +1,227 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"fast-gin/utils/oidc"
+	"fast-gin/utils/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"testing"
+	"time"
+)
+
+const redirectURL = "http://app.test/v1/users/oidc/callback"
+
+func newProvider(t *testing.T, idp *oidctest.Server) *oidc.Provider {
+	t.Helper()
+	discovery, err := oidc.Discover(context.Background(), idp.URL)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	return &oidc.Provider{
+		Discovery:    discovery,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email"},
+	}
+}
+
+func newIdP(t *testing.T) *oidctest.Server {
+	idp := oidctest.NewServer("fast-gin", "s3cret/+=")
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func TestDiscover(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+	if p.Issuer != idp.URL || p.TokenEndpoint != idp.URL+"/token" || p.JWKSURI != idp.URL+"/jwks" {
+		t.Fatalf("unexpected discovery: %+v", p.Discovery)
+	}
+
+	_, err := oidc.Discover(context.Background(), idp.URL+"/other")
+	if err == nil {
+		t.Fatal("Discover of another issuer succeeded")
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+	ctx := context.Background()
+
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL := p.AuthCodeURL("state-1", "nonce-1", oidc.Challenge(verifier))
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+
+	res, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := p.Verify(ctx, res.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != idp.User.Subject || claims.Email != idp.User.Email || !claims.EmailVerified {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	// Codes are single use
+	_, err = p.Exchange(ctx, code, verifier)
+	if err == nil {
+		t.Fatal("second Exchange of the same code succeeded")
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+
+	verifier, _ := oidc.NewVerifier()
+	other, _ := oidc.NewVerifier()
+	code, _, err := idp.Authorize(p.AuthCodeURL("state", "nonce", oidc.Challenge(verifier)))
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	_, err = p.Exchange(context.Background(), code, other)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange with another verifier: %v, want invalid_grant", err)
+	}
+}
+
+func TestVerifyNonceMismatch(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+
+	_, err := p.Verify(context.Background(), idp.Sign("nonce-1"), "nonce-2")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("Verify with another nonce: %v, want nonce mismatch", err)
+	}
+	_, err = p.Verify(context.Background(), idp.Sign(""), "")
+	if err == nil {
+		t.Fatal("Verify without nonce succeeded")
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+
+	cases := map[string]func(jwt.MapClaims){
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.test" },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"expired":  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"subject":  func(c jwt.MapClaims) { delete(c, "sub") },
+		"azp":      func(c jwt.MapClaims) { c["aud"] = []string{idp.ClientID, "another-client"} },
+	}
+	for name, change := range cases {
+		idp.Claims = change
+		_, err := p.Verify(context.Background(), idp.Sign("nonce"), "nonce")
+		if err == nil {
+			t.Errorf("%s: Verify succeeded", name)
+		}
+	}
+}
+
+func TestVerifyBadSignature(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+	ctx := context.Background()
+
+	// Signed by a key the provider does not publish, under its kid
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   idp.URL,
+		"sub":   idp.User.Subject,
+		"aud":   idp.ClientID,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce",
+	})
+	token.Header["kid"] = idp.KID()
+	forged, err := token.SignedString(forger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Verify(ctx, forged, "nonce")
+	if err == nil {
+		t.Fatal("Verify of a forged token succeeded")
+	}
+
+	// Payload changed after signing
+	parts := strings.Split(idp.Sign("nonce"), ".")
+	idp.Claims = func(c jwt.MapClaims) { c["sub"] = "someone-else" }
+	parts[1] = strings.Split(idp.Sign("nonce"), ".")[1]
+	_, err = p.Verify(ctx, strings.Join(parts, "."), "nonce")
+	if err == nil {
+		t.Fatal("Verify of a tampered token succeeded")
+	}
+
+	// Algorithm confusion
+	none, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": idp.URL, "sub": "x", "aud": idp.ClientID,
+		"exp": time.Now().Add(time.Minute).Unix(), "nonce": "nonce",
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	_, err = p.Verify(ctx, none, "nonce")
+	if err == nil {
+		t.Fatal("Verify of an unsigned token succeeded")
+	}
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	idp := newIdP(t)
+	p := newProvider(t, idp)
+	ctx := context.Background()
+
+	old := idp.Sign("nonce")
+	_, err := p.Verify(ctx, old, "nonce")
+	if err != nil {
+		t.Fatalf("Verify before rotation: %v", err)
+	}
+
+	// The new kid is not cached yet, the JWKS is fetched again
+	idp.Rotate(true)
+	_, err = p.Verify(ctx, idp.Sign("nonce"), "nonce")
+	if err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	_, err = p.Verify(ctx, old, "nonce")
+	if err != nil {
+		t.Fatalf("Verify with the still published key: %v", err)
+	}
+
+	// Keys the provider withdrew are forgotten on the next fetch
+	idp.Rotate(false)
+	_, err = p.Verify(ctx, idp.Sign("nonce"), "nonce")
+	if err != nil {
+		t.Fatalf("Verify after second rotation: %v", err)
+	}
+	_, err = p.Verify(ctx, old, "nonce")
+	if err == nil {
+		t.Fatal("Verify with a withdrawn key succeeded")
+	}
+
+	// A kid the provider never published
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{})
+	token.Header["kid"] = "unknown"
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	unknown, _ := token.SignedString(key)
+	_, err = p.Verify(ctx, unknown, "nonce")
+	if err == nil || !strings.Contains(err.Error(), "unknown kid") {
+		t.Fatalf("Verify with an unknown kid: %v, want unknown kid", err)
+	}
+}
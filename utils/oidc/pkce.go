@@ -0,0 +1,18 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fast-gin/utils/random"
+)
+
+// NewVerifier returns a PKCE code verifier (RFC 7636), 43 characters
+func NewVerifier() (string, error) {
+	return random.Token(32)
+}
+
+// Challenge returns the S256 challenge of verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package pwd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fast-gin/config"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+var errInvalidArgon2 = errors.New("invalid argon2id hash")
+
+// Argon2idHasher produces PHC strings:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewArgon2idHasher fills parameters left zero with the RFC 9106 second
+// recommended option, scaled down to 64 MiB
+func NewArgon2idHasher(cfg config.Argon2) Argon2idHasher {
+	h := Argon2idHasher{
+		Memory:      cfg.Memory,
+		Iterations:  cfg.Iterations,
+		Parallelism: cfg.Parallelism,
+		SaltLength:  cfg.SaltLength,
+		KeyLength:   cfg.KeyLength,
+	}
+	if h.Memory == 0 {
+		h.Memory = 64 * 1024
+	}
+	if h.Iterations == 0 {
+		h.Iterations = 3
+	}
+	if h.Parallelism == 0 {
+		h.Parallelism = 2
+	}
+	if h.SaltLength == 0 {
+		h.SaltLength = 16
+	}
+	if h.KeyLength == 0 {
+		h.KeyLength = 32
+	}
+	return h
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (Argon2idHasher) Verify(hash string, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h Argon2idHasher) Outdated(hash string) bool {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.Memory || params.Iterations != h.Iterations ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength || uint32(len(key)) != h.KeyLength
+}
+
+func (Argon2idHasher) Owns(hash string) bool {
+	return hasPrefix(hash, argon2idPrefix)
+}
+
+func decodeArgon2id(hash string) (params Argon2idHasher, salt []byte, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidArgon2
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, errInvalidArgon2
+	}
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidArgon2
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,35 @@
+package pwd
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxLength is the longest input bcrypt reads, in bytes
+const bcryptMaxLength = 72
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashedPassword), err
+}
+
+func (BcryptHasher) Verify(hash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h BcryptHasher) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+func (BcryptHasher) Owns(hash string) bool {
+	return hasPrefix(hash, "$2a$", "$2b$", "$2y$")
+}
@@ -1,20 +1,124 @@
 package pwd
 
 import (
+	"errors"
+	"fast-gin/config"
+	"fmt"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"sync"
 )
 
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher hashes passwords with one algorithm
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash of this algorithm
+	Verify(hash string, password string) (bool, error)
+	// Outdated reports whether hash uses other parameters than the hasher
+	Outdated(hash string) bool
+	// Owns reports whether hash was made by this algorithm
+	Owns(hash string) bool
+}
+
+var (
+	mu      sync.RWMutex
+	current Hasher = BcryptHasher{Cost: 10}
+	hashers        = []Hasher{BcryptHasher{}, Argon2idHasher{}}
+)
+
+// Configure selects the hasher new passwords are hashed with and loads the
+// password policy
+func Configure(cfg config.Password) error {
+	apply, err := Prepare(cfg)
+	if err != nil {
+		return err
+	}
+	apply()
+	return nil
+}
+
+// Prepare builds the hasher and the password policy of cfg, apply makes them
+// current. Nothing changes when cfg is rejected.
+func Prepare(cfg config.Password) (apply func(), err error) {
+	var h Hasher
+	switch cfg.Hasher {
+	case config.BCRYPT, "":
+		cost := cfg.BcryptCost
+		if cost == 0 {
+			cost = 10
+		}
+		if cost < 4 || cost > 31 {
+			return nil, fmt.Errorf("bcrypt cost must be within 4-31, got %d", cost)
+		}
+		h = BcryptHasher{Cost: cost}
+	case config.ARGON2ID:
+		h = NewArgon2idHasher(cfg.Argon2)
+	default:
+		return nil, fmt.Errorf("unsupported password hasher: %s", cfg.Hasher)
+	}
+
+	policy, err := loadPolicy(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := h.(BcryptHasher); ok && (policy.MaxLength == 0 || policy.MaxLength > bcryptMaxLength) {
+		policy.MaxLength = bcryptMaxLength
+	}
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		current = h
+		currentPolicy = policy
+	}, nil
+}
+
+func hasher() Hasher {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
 func Encrypt(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hasher().Hash(password)
 	if err != nil {
 		logrus.Errorf("Failed to encrypt password: %s", err)
 		return "", err
 	}
-	return string(hashedPassword), nil
+	return hashedPassword, nil
 }
 
+// Validate checks password against a hash of any supported algorithm
 func Validate(hashedPassword string, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	for _, h := range hashers {
+		if h.Owns(hashedPassword) {
+			ok, err := h.Verify(hashedPassword, password)
+			if err != nil {
+				logrus.Errorf("Failed to validate password: %s", err)
+			}
+			return ok
+		}
+	}
+	if hashedPassword != "" {
+		logrus.Errorf("Failed to validate password: %s", ErrUnknownHash)
+	}
+	return false
+}
+
+// NeedsRehash reports whether hash should be replaced by one of the
+// configured hasher, callers rehash right after a successful Validate
+func NeedsRehash(hashedPassword string) bool {
+	h := hasher()
+	return !h.Owns(hashedPassword) || h.Outdated(hashedPassword)
+}
+
+func hasPrefix(hash string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,98 @@
+package pwd
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fast-gin/config"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+var ErrBreached = errors.New("Password has appeared in a data breach, please choose another one")
+
+type policy struct {
+	config.PasswordPolicy
+	breached map[string]struct{} // SHA-1 hex, upper case
+}
+
+var currentPolicy = policy{PasswordPolicy: config.PasswordPolicy{MinLength: 8, MaxLength: 72}}
+
+// loadPolicy reads the breached password list, lines are either plain
+// passwords or SHA-1 hex digests optionally followed by ":count" as in the
+// Have I Been Pwned downloads
+func loadPolicy(cfg config.PasswordPolicy) (p policy, err error) {
+	p.PasswordPolicy = cfg
+	if cfg.Breached == "" {
+		return
+	}
+	file, err := os.Open(cfg.Breached)
+	if err != nil {
+		return p, fmt.Errorf("breached password list: %w", err)
+	}
+	defer file.Close()
+
+	p.breached = make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		digest, _, _ := strings.Cut(line, ":")
+		if _, err := hex.DecodeString(digest); err != nil || len(digest) != 40 {
+			digest = sha1Hex(line)
+		}
+		p.breached[strings.ToUpper(digest)] = struct{}{}
+	}
+	return p, scanner.Err()
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// CheckPolicy returns why password is not acceptable, the message can be
+// shown to the user as it is
+func CheckPolicy(password string) error {
+	mu.RLock()
+	p := currentPolicy
+	mu.RUnlock()
+
+	// The minimum is what the user counts, the maximum is what the hasher
+	// stores
+	if p.MinLength > 0 && len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("Password must be at least %d characters", p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("Password must be at most %d bytes (UTF-8)", p.MaxLength)
+	}
+
+	if p.MinClasses > 0 {
+		var lower, upper, digit, symbol int
+		for _, r := range password {
+			switch {
+			case unicode.IsLower(r):
+				lower = 1
+			case unicode.IsUpper(r):
+				upper = 1
+			case unicode.IsDigit(r):
+				digit = 1
+			default:
+				symbol = 1
+			}
+		}
+		if lower+upper+digit+symbol < p.MinClasses {
+			return fmt.Errorf("Password must contain %d of lower case letters, upper case letters, digits and symbols", p.MinClasses)
+		}
+	}
+
+	if _, ok := p.breached[sha1Hex(password)]; ok {
+		return ErrBreached
+	}
+	return nil
+}
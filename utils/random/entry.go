@@ -0,0 +1,15 @@
+package random
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// Token returns a URL-safe random string built from n bytes of entropy
+func Token(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -12,7 +12,12 @@ type Response struct {
 	Msg  string `json:"msg"`
 }
 
+// writtenKey holds the code and message of the response, so that
+// middlewares can tell the outcome of a request
+const writtenKey = "response_written"
+
 func OK(c *gin.Context, data any, msg string) {
+	c.Set(writtenKey, Response{Code: 0, Msg: msg})
 	c.JSON(http.StatusOK, Response{
 		Code: 0,
 		Data: data,
@@ -36,6 +41,7 @@ func OKWithList(c *gin.Context, list any, count int64) {
 }
 
 func Fail(c *gin.Context, code int, msg string) {
+	c.Set(writtenKey, Response{Code: code, Msg: msg})
 	c.JSON(http.StatusOK, Response{
 		Code: code,
 		Data: gin.H{},
@@ -51,3 +57,14 @@ func FailWithErr(c *gin.Context, err error) {
 	msg := validate.ValidateError(err)
 	Fail(c, 7, msg)
 }
+
+// Written returns the code and message written by OK or Fail, false when
+// the handler wrote nothing through this package
+func Written(c *gin.Context) (Response, bool) {
+	value, ok := c.Get(writtenKey)
+	if !ok {
+		return Response{}, false
+	}
+	res, ok := value.(Response)
+	return res, ok
+}
@@ -0,0 +1,11 @@
+package sha256
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func GetSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}